@@ -0,0 +1,72 @@
+//Package bencode implements encoding and decoding of bencoded data as used by .torrent files.
+//
+//Struct fields are mapped to dictionary keys with the "bencode" struct tag, e.g. `bencode:"piece length,omitempty"`. Fields without a tag use the field name; a tag of "-" skips the field.
+package bencode
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//RawMessage is a raw encoded bencode value. It can be used to delay decoding or to keep the exact bytes of a value, e.g. to hash a torrent's info dictionary.
+type RawMessage []byte
+
+//SyntaxError describes malformed bencoded data.
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return "bencode: " + e.Msg + " at offset " + strconv.Itoa(e.Offset)
+}
+
+//UnmarshalTypeError describes a bencode value that cannot be stored in a Go value of the given type.
+type UnmarshalTypeError struct {
+	Value string
+	Type  reflect.Type
+}
+
+func (e *UnmarshalTypeError) Error() string {
+	return "bencode: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
+}
+
+//UnsupportedTypeError is returned by Marshal for values that have no bencode representation.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "bencode: unsupported type " + e.Type.String()
+}
+
+var rawMessageType = reflect.TypeOf(RawMessage(nil))
+
+type field struct {
+	key       string
+	index     int
+	omitEmpty bool
+}
+
+//structFields returns the bencoded fields of struct type t, sorted by key as dictionaries must be.
+func structFields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		key, opts, _ := strings.Cut(f.Tag.Get("bencode"), ",")
+		if key == "-" && opts == "" {
+			continue
+		}
+		if key == "" {
+			key = f.Name
+		}
+		fields = append(fields, field{key: key, index: i, omitEmpty: opts == "omitempty"})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+	return fields
+}
@@ -0,0 +1,166 @@
+package bencode
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type file struct {
+	Length int64    `bencode:"length"`
+	Path   []string `bencode:"path"`
+}
+
+type info struct {
+	Name        string  `bencode:"name"`
+	PieceLength int64   `bencode:"piece length"`
+	Pieces      []byte  `bencode:"pieces"`
+	Hash        [4]byte `bencode:"hash"`
+	Private     bool    `bencode:"private,omitempty"`
+	Files       []file  `bencode:"files,omitempty"`
+	Meta        map[string]string
+	Skipped     string  `bencode:"-"`
+	Count       *uint16 `bencode:"count,omitempty"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	count := uint16(7)
+	in := info{
+		Name:        "album",
+		PieceLength: 1 << 18,
+		Pieces:      []byte("\x00\x01\x02"),
+		Hash:        [4]byte{0xde, 0xad, 0xbe, 0xef},
+		Private:     true,
+		Files:       []file{{Length: 3, Path: []string{"cd1", "01.flac"}}, {Length: -1, Path: []string{}}},
+		Meta:        map[string]string{"b": "2", "a": "1"},
+		Skipped:     "not encoded",
+		Count:       &count,
+	}
+	data, err := Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "d4:Metad1:a1:11:b1:2e5:counti7e5:filesld6:lengthi3e4:pathl3:cd17:01.flaceed6:lengthi-1e4:pathleee4:hash4:\xde\xad\xbe\xef4:name5:album12:piece lengthi262144e6:pieces3:\x00\x01\x027:privatei1ee"
+	if string(data) != want {
+		t.Fatalf("Marshal = %q, want %q", data, want)
+	}
+	var out info
+	if err := Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	in.Skipped = ""
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("Unmarshal = %+v, want %+v", out, in)
+	}
+}
+
+func TestUnmarshalInterface(t *testing.T) {
+	var v interface{}
+	if err := Unmarshal([]byte("d1:ai-3e1:bl1:xi0eee"), &v); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"a": int64(-3), "b": []interface{}{"x", int64(0)}}
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("Unmarshal = %#v, want %#v", v, want)
+	}
+}
+
+func TestUnmarshalMalformed(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"x",
+		"i",
+		"ie",
+		"i01e",
+		"i-0e",
+		"i1x",
+		"i1ei2e",
+		"3:ab",
+		"03:abc",
+		"+3:abc",
+		"-1:a",
+		":a",
+		"l",
+		"li1e",
+		"d1:a",
+		"di1ei2ee",
+		strings.Repeat("l", 10<<20),
+		strings.Repeat("d1:a", maxDepth+1),
+	} {
+		var v interface{}
+		err := Unmarshal([]byte(data), &v)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			name := data
+			if len(name) > 20 {
+				name = name[:20] + "..."
+			}
+			t.Errorf("Unmarshal(%q) = %v, want a SyntaxError", name, err)
+		}
+	}
+}
+
+func TestUnmarshalDeepIntoStruct(t *testing.T) {
+	var v struct {
+		Files [][]string `bencode:"files"`
+		Rest  []info     `bencode:"rest"`
+	}
+	data := "d5:other" + strings.Repeat("l", 1<<20) + "e"
+	var syntaxErr *SyntaxError
+	if err := Unmarshal([]byte(data), &v); !errors.As(err, &syntaxErr) {
+		t.Fatalf("Unmarshal = %v, want a SyntaxError", err)
+	}
+	nested := strings.Repeat("l", maxDepth) + strings.Repeat("e", maxDepth)
+	var generic interface{}
+	if err := Unmarshal([]byte(nested), &generic); err != nil {
+		t.Fatalf("Unmarshal of %d nested lists: %v", maxDepth, err)
+	}
+}
+
+func TestUnmarshalTypeErrors(t *testing.T) {
+	var typeErr *UnmarshalTypeError
+	var n int
+	if err := Unmarshal([]byte("3:abc"), &n); !errors.As(err, &typeErr) {
+		t.Errorf("string into int: %v, want an UnmarshalTypeError", err)
+	}
+	var small int8
+	if err := Unmarshal([]byte("i300e"), &small); !errors.As(err, &typeErr) {
+		t.Errorf("overflowing int8: %v, want an UnmarshalTypeError", err)
+	}
+	var hash [4]byte
+	if err := Unmarshal([]byte("3:abc"), &hash); !errors.As(err, &typeErr) {
+		t.Errorf("short string into [4]byte: %v, want an UnmarshalTypeError", err)
+	}
+	if err := Unmarshal([]byte("4:abcd"), &hash); err != nil || string(hash[:]) != "abcd" {
+		t.Errorf("Unmarshal into [4]byte = %q, %v", hash, err)
+	}
+}
+
+func TestRawMessageInfoHash(t *testing.T) {
+	const infoDict = "d6:lengthi5e4:name5:a.txt12:piece lengthi16384e6:pieces20:0123456789abcdefghij7:privatei1e6:source3:OPSe"
+	data := "d8:announce14:http://tracker4:info" + infoDict + "e"
+	var torrent struct {
+		Announce string     `bencode:"announce"`
+		Info     RawMessage `bencode:"info"`
+	}
+	if err := Unmarshal([]byte(data), &torrent); err != nil {
+		t.Fatal(err)
+	}
+	if string(torrent.Info) != infoDict {
+		t.Fatalf("Info = %q, want %q", torrent.Info, infoDict)
+	}
+	hash := sha1.Sum(torrent.Info)
+	if got := hex.EncodeToString(hash[:]); got != "9bcf9b45ce72ac74ca01a0c610f38c6b0fe63b74" {
+		t.Fatalf("info hash = %s", got)
+	}
+	reencoded, err := Marshal(torrent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(reencoded) != data {
+		t.Fatalf("Marshal = %q, want %q", reencoded, data)
+	}
+}
@@ -0,0 +1,341 @@
+package bencode
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+//Unmarshal decodes the bencoded data into the value pointed to by v. Integers decode into Go integers, strings into strings, byte slices or byte arrays of the same length, lists into slices or arrays and dictionaries into structs or maps with string keys. Decoded into an empty interface, values become int64, string, []interface{} and map[string]interface{}.
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("bencode: Unmarshal requires a non-nil pointer")
+	}
+	d := &decoder{data: data}
+	if err := d.value(rv.Elem()); err != nil {
+		return err
+	}
+	if d.pos != len(d.data) {
+		return d.syntaxError("trailing data")
+	}
+	return nil
+}
+
+//maxDepth is how deeply lists and dictionaries may be nested. Real .torrent files need only a few levels; the limit keeps hostile input from exhausting the stack.
+const maxDepth = 512
+
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (d *decoder) syntaxError(msg string) error {
+	return &SyntaxError{Offset: d.pos, Msg: msg}
+}
+
+//enter moves into a list or dictionary, failing once the nesting gets too deep. Each call must be paired with leave.
+func (d *decoder) enter() error {
+	d.depth++
+	if d.depth > maxDepth {
+		return d.syntaxError("exceeded max nesting depth")
+	}
+	return nil
+}
+
+func (d *decoder) leave() {
+	d.depth--
+}
+
+func (d *decoder) peek() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, d.syntaxError("unexpected end of data")
+	}
+	return d.data[d.pos], nil
+}
+
+//value decodes the next value into v.
+func (d *decoder) value(v reflect.Value) error {
+	if v.Type() == rawMessageType {
+		start := d.pos
+		if err := d.skip(); err != nil {
+			return err
+		}
+		v.SetBytes(append([]byte(nil), d.data[start:d.pos]...))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.value(v.Elem())
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		x, err := d.any()
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(x))
+		return nil
+	}
+	c, err := d.peek()
+	if err != nil {
+		return err
+	}
+	switch {
+	case c == 'i':
+		return d.integer(v)
+	case c >= '0' && c <= '9':
+		return d.str(v)
+	case c == 'l':
+		return d.list(v)
+	case c == 'd':
+		return d.dict(v)
+	}
+	return d.syntaxError("invalid character " + strconv.QuoteRune(rune(c)))
+}
+
+func (d *decoder) readInt() (int64, error) {
+	d.pos++
+	end := d.pos
+	for end < len(d.data) && d.data[end] != 'e' {
+		end++
+	}
+	if end == len(d.data) {
+		return 0, d.syntaxError("unterminated integer")
+	}
+	digits := string(d.data[d.pos:end])
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || (len(digits) > 1 && (digits[0] == '0' || digits[:2] == "-0")) {
+		return 0, d.syntaxError("invalid integer " + strconv.Quote(digits))
+	}
+	d.pos = end + 1
+	return n, nil
+}
+
+func (d *decoder) readString() ([]byte, error) {
+	colon := d.pos
+	for colon < len(d.data) && d.data[colon] != ':' {
+		colon++
+	}
+	if colon == len(d.data) {
+		return nil, d.syntaxError("unterminated string length")
+	}
+	digits := string(d.data[d.pos:colon])
+	length, err := strconv.Atoi(digits)
+	if err != nil || digits[0] < '0' || digits[0] > '9' || (len(digits) > 1 && digits[0] == '0') || length > len(d.data)-colon-1 {
+		return nil, d.syntaxError("invalid string length " + strconv.Quote(digits))
+	}
+	d.pos = colon + 1 + length
+	return d.data[colon+1 : d.pos], nil
+}
+
+func (d *decoder) integer(v reflect.Value) error {
+	n, err := d.readInt()
+	if err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(n) {
+			return &UnmarshalTypeError{"integer " + strconv.FormatInt(n, 10), v.Type()}
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n < 0 || v.OverflowUint(uint64(n)) {
+			return &UnmarshalTypeError{"integer " + strconv.FormatInt(n, 10), v.Type()}
+		}
+		v.SetUint(uint64(n))
+	case reflect.Bool:
+		v.SetBool(n != 0)
+	default:
+		return &UnmarshalTypeError{"integer", v.Type()}
+	}
+	return nil
+}
+
+func (d *decoder) str(v reflect.Value) error {
+	s, err := d.readString()
+	if err != nil {
+		return err
+	}
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(string(s))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		v.SetBytes(append([]byte(nil), s...))
+	case v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8:
+		if len(s) != v.Len() {
+			return &UnmarshalTypeError{"string of length " + strconv.Itoa(len(s)), v.Type()}
+		}
+		reflect.Copy(v, reflect.ValueOf(s))
+	default:
+		return &UnmarshalTypeError{"string", v.Type()}
+	}
+	return nil
+}
+
+func (d *decoder) list(v reflect.Value) error {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return &UnmarshalTypeError{"list", v.Type()}
+	}
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	d.pos++
+	i := 0
+	for {
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		if c == 'e' {
+			d.pos++
+			break
+		}
+		if v.Kind() == reflect.Slice {
+			if i >= v.Len() {
+				v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+			}
+			if err := d.value(v.Index(i)); err != nil {
+				return err
+			}
+		} else if i < v.Len() {
+			if err := d.value(v.Index(i)); err != nil {
+				return err
+			}
+		} else if err := d.skip(); err != nil {
+			return err
+		}
+		i++
+	}
+	if v.Kind() == reflect.Slice {
+		if v.IsNil() {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+		}
+		v.SetLen(i)
+	}
+	return nil
+}
+
+func (d *decoder) dict(v reflect.Value) error {
+	var fields map[string]int
+	switch {
+	case v.Kind() == reflect.Struct:
+		fields = map[string]int{}
+		for _, f := range structFields(v.Type()) {
+			fields[f.key] = f.index
+		}
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	default:
+		return &UnmarshalTypeError{"dictionary", v.Type()}
+	}
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	d.pos++
+	for {
+		c, err := d.peek()
+		if err != nil {
+			return err
+		}
+		if c == 'e' {
+			d.pos++
+			return nil
+		}
+		if c < '0' || c > '9' {
+			return d.syntaxError("dictionary key is not a string")
+		}
+		key, err := d.readString()
+		if err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Map {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(string(key)).Convert(v.Type().Key()), elem)
+			continue
+		}
+		if index, ok := fields[string(key)]; ok {
+			if err := d.value(v.Field(index)); err != nil {
+				return err
+			}
+		} else if err := d.skip(); err != nil {
+			return err
+		}
+	}
+}
+
+//any decodes the next value into its generic Go representation.
+func (d *decoder) any() (interface{}, error) {
+	c, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c == 'i':
+		return d.readInt()
+	case c >= '0' && c <= '9':
+		s, err := d.readString()
+		return string(s), err
+	case c == 'l':
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		d.pos++
+		list := []interface{}{}
+		for {
+			if c, err := d.peek(); err != nil {
+				return nil, err
+			} else if c == 'e' {
+				d.pos++
+				return list, nil
+			}
+			x, err := d.any()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, x)
+		}
+	case c == 'd':
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		d.pos++
+		dict := map[string]interface{}{}
+		for {
+			if c, err := d.peek(); err != nil {
+				return nil, err
+			} else if c == 'e' {
+				d.pos++
+				return dict, nil
+			}
+			key, err := d.readString()
+			if err != nil {
+				return nil, err
+			}
+			x, err := d.any()
+			if err != nil {
+				return nil, err
+			}
+			dict[string(key)] = x
+		}
+	}
+	return nil, d.syntaxError("invalid character " + strconv.QuoteRune(rune(c)))
+}
+
+//skip moves past the next value without decoding it.
+func (d *decoder) skip() error {
+	_, err := d.any()
+	return err
+}
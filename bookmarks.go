@@ -0,0 +1,90 @@
+package whatapi
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+)
+
+//BookmarkType identifies the kind of item a bookmark refers to.
+type BookmarkType string
+
+const (
+	TorrentBookmark BookmarkType = "torrent"
+	ArtistBookmark  BookmarkType = "artist"
+	CollageBookmark BookmarkType = "collage"
+	RequestBookmark BookmarkType = "request"
+)
+
+//AddBookmark bookmarks the item of the provided type and id for the current user. For torrents the id is the torrent group id.
+func (w *WhatAPI) AddBookmark(ctx context.Context, bookmarkType BookmarkType, id int) error {
+	return w.changeBookmark(ctx, "add", bookmarkType, id)
+}
+
+//RemoveBookmark removes the current user's bookmark of the item with the provided type and id.
+func (w *WhatAPI) RemoveBookmark(ctx context.Context, bookmarkType BookmarkType, id int) error {
+	return w.changeBookmark(ctx, "remove", bookmarkType, id)
+}
+
+func (w *WhatAPI) changeBookmark(ctx context.Context, action string, bookmarkType BookmarkType, id int) error {
+	params := url.Values{}
+	params.Set("action", action)
+	params.Set("type", string(bookmarkType))
+	params.Set("id", strconv.Itoa(id))
+	_, err := w.getAction(ctx, "bookmarks.php", params)
+	return err
+}
+
+//BookmarkedCollage is a collage on the current user's bookmarks page.
+type BookmarkedCollage struct {
+	ID   int
+	Name string
+}
+
+//BookmarkedRequest is a request on the current user's bookmarks page.
+type BookmarkedRequest struct {
+	ID    int
+	Title string
+}
+
+var (
+	collageLink = regexp.MustCompile(`(?s)<a href="collages\.php\?id=(\d+)"[^>]*>(.*?)</a>`)
+	requestLink = regexp.MustCompile(`(?s)<a href="requests\.php\?action=view&(?:amp;)?id=(\d+)"[^>]*>(.*?)</a>`)
+)
+
+//GetCollageBookmarks retrieves the collages the current user has bookmarked. ajax.php answers with the HTML bookmarks page for collages, so they are read from that page directly.
+func (w *WhatAPI) GetCollageBookmarks(ctx context.Context) ([]BookmarkedCollage, error) {
+	var collages []BookmarkedCollage
+	err := w.scrapeBookmarks(ctx, "collages", collageLink, func(id int, text string) {
+		collages = append(collages, BookmarkedCollage{ID: id, Name: text})
+	})
+	return collages, err
+}
+
+//GetRequestBookmarks retrieves the requests the current user has bookmarked. ajax.php answers with the HTML bookmarks page for requests, so they are read from that page directly.
+func (w *WhatAPI) GetRequestBookmarks(ctx context.Context) ([]BookmarkedRequest, error) {
+	var requests []BookmarkedRequest
+	err := w.scrapeBookmarks(ctx, "requests", requestLink, func(id int, text string) {
+		requests = append(requests, BookmarkedRequest{ID: id, Title: text})
+	})
+	return requests, err
+}
+
+//scrapeBookmarks calls add once for every item linked by link on the bookmarks page of the given type, in page order.
+func (w *WhatAPI) scrapeBookmarks(ctx context.Context, bookmarkType string, link *regexp.Regexp, add func(id int, text string)) error {
+	body, err := w.getAction(ctx, "bookmarks.php", url.Values{"type": {bookmarkType}})
+	if err != nil {
+		return err
+	}
+	seen := map[int]bool{}
+	for _, match := range link.FindAllSubmatch(body, -1) {
+		id, _ := strconv.Atoi(string(match[1]))
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		add(id, pageText(match[2]))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package whatapi
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//CommentPage identifies the kind of page a comment is posted on.
+type CommentPage string
+
+const (
+	TorrentComments CommentPage = "torrents"
+	ArtistComments  CommentPage = "artist"
+	CollageComments CommentPage = "collages"
+	RequestComments CommentPage = "requests"
+)
+
+//PostComment posts a comment with the provided body on the page of the provided kind and id, e.g. a torrent group or an artist.
+func (w *WhatAPI) PostComment(ctx context.Context, page CommentPage, pageID int, body string) error {
+	params := url.Values{}
+	params.Set("action", "take_post")
+	params.Set("page", string(page))
+	params.Set("pageid", strconv.Itoa(pageID))
+	params.Set("body", body)
+	_, err := w.postAction(ctx, "comments.php", params)
+	return err
+}
+
+var (
+	commentStart  = regexp.MustCompile(`<table class="forum_post[^"]*" id="post(\d+)"`)
+	commentAuthor = regexp.MustCompile(`(?s)<strong>.*?<a href="user\.php\?id=(\d+)"[^>]*>(.*?)</a>`)
+	commentTime   = regexp.MustCompile(`<span class="time[^"]*" title="([^"]*)"`)
+	commentBody   = regexp.MustCompile(`(?s)<div id="content\d+">(.*?)</div>\s*</td>`)
+	commentPages  = regexp.MustCompile(`[a-z]+\.php\?id=\d+&(?:amp;)?page=(\d+)`)
+)
+
+//GetArtistComments retrieves a page of comments on the artist with the provided id. The API has no endpoint for artist comments, so they are read from the artist page; Body holds the rendered HTML and BbBody is left empty.
+func (w *WhatAPI) GetArtistComments(ctx context.Context, artistID, page int) (Comments, error) {
+	if page < 1 {
+		page = 1
+	}
+	params := url.Values{}
+	params.Set("id", strconv.Itoa(artistID))
+	params.Set("page", strconv.Itoa(page))
+	body, err := w.getAction(ctx, "artist.php", params)
+	if err != nil {
+		return Comments{}, err
+	}
+	return parseComments(body, page), nil
+}
+
+//parseComments reads the comments rendered on a page, along with the number of pages from its page links.
+func parseComments(body []byte, page int) Comments {
+	comments := Comments{Page: page, Pages: page}
+	for _, match := range commentPages.FindAllSubmatch(body, -1) {
+		if n, _ := strconv.Atoi(string(match[1])); n > comments.Pages {
+			comments.Pages = n
+		}
+	}
+	starts := commentStart.FindAllSubmatchIndex(body, -1)
+	for i, start := range starts {
+		end := len(body)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		post := body[start[0]:end]
+		comment := Comment{}
+		comment.PostID, _ = strconv.Atoi(string(body[start[2]:start[3]]))
+		if match := commentAuthor.FindSubmatch(post); match != nil {
+			comment.Author.AuthorID, _ = strconv.Atoi(string(match[1]))
+			comment.Author.AuthorName = pageText(match[2])
+		}
+		if match := commentTime.FindSubmatch(post); match != nil {
+			comment.AddedTime = pageText(match[1])
+		}
+		if match := commentBody.FindSubmatch(post); match != nil {
+			comment.Body = strings.TrimSpace(string(match[1]))
+		}
+		comments.Comments = append(comments.Comments, comment)
+	}
+	return comments
+}
@@ -0,0 +1,41 @@
+package whatapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+const artistCommentsPage = `<html><title>Tool</title>
+<div class="linkbox"><strong>1</strong> <a href="artist.php?id=3&amp;page=2#comments">2</a> <a href="artist.php?id=3&amp;page=3#comments">3</a></div>
+<table class="forum_post box vertical_margin" id="post10">
+	<tr class="colhead_dark"><td colspan="2">
+		<div style="float: left;"><a class="post_id" href="artist.php?id=3&amp;postid=10#post10">#10</a>
+		<strong><a href="user.php?id=5">alice</a></strong> <span class="time tooltip" title="Jan 02 2020, 10:00">5 years ago</span></div>
+	</td></tr>
+	<tr><td class="body" valign="top">
+		<div id="content10">
+			Is the <div class="quote">vinyl</div> rip a trump?
+		</div>
+	</td></tr>
+</table>
+<table class="forum_post box vertical_margin" id="post11">
+	<tr class="colhead_dark"><td colspan="2">
+		<strong><a href="user.php?id=6">bob &amp; co</a></strong> <span class="time" title="Jan 03 2020, 11:00">5 years ago</span>
+	</td></tr>
+	<tr><td class="body" valign="top"><div id="content11">No.</div></td></tr>
+</table>
+</html>`
+
+func TestParseComments(t *testing.T) {
+	comments := parseComments([]byte(artistCommentsPage), 1)
+	want := Comments{Page: 1, Pages: 3, Comments: []Comment{
+		{PostID: 10, AddedTime: "Jan 02 2020, 10:00", Body: `Is the <div class="quote">vinyl</div> rip a trump?`, Author: PostAuthor{AuthorID: 5, AuthorName: "alice"}},
+		{PostID: 11, AddedTime: "Jan 03 2020, 11:00", Body: "No.", Author: PostAuthor{AuthorID: 6, AuthorName: "bob & co"}},
+	}}
+	if !reflect.DeepEqual(comments, want) {
+		t.Fatalf("parseComments = %+v, want %+v", comments, want)
+	}
+	if empty := parseComments([]byte(`<html></html>`), 4); empty.Pages != 4 || len(empty.Comments) != 0 {
+		t.Fatalf("parseComments of an empty page = %+v", empty)
+	}
+}
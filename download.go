@@ -0,0 +1,235 @@
+package whatapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//MaxTorrentSize is the largest .torrent file DownloadTorrent accepts.
+const MaxTorrentSize = 10 << 20
+
+//DownloadTorrent downloads the .torrent file for the torrent with the provided id. If useToken is set, a freeleech token is spent on the torrent, unless the site does not allow one, the torrent is already freeleech or the current user has no tokens left. The response is checked to be a bencoded torrent rather than an HTML error page.
+func (w *WhatAPI) DownloadTorrent(ctx context.Context, id int, useToken bool) ([]byte, error) {
+	if useToken {
+		torrent, err := w.GetTorrent(ctx, id, url.Values{})
+		if err != nil {
+			return nil, err
+		}
+		if err := w.checkTorrentTokenUse(ctx, torrent); err != nil {
+			return nil, err
+		}
+	}
+	return w.fetchTorrent(ctx, id, useToken)
+}
+
+//DownloadSearchTorrent downloads the .torrent file for a torrent from search results like DownloadTorrent. A token is only spent if the result's CanUseToken allows it and the torrent is not already freeleech.
+func (w *WhatAPI) DownloadSearchTorrent(ctx context.Context, torrent SearchTorrent, useToken bool) ([]byte, error) {
+	if useToken {
+		if err := w.checkSearchTokenUse(ctx, torrent); err != nil {
+			return nil, err
+		}
+	}
+	return w.fetchTorrent(ctx, torrent.TorrentID, useToken)
+}
+
+//tokenSearchPages caps how many pages of search results checkTorrentTokenUse looks through.
+const tokenSearchPages = 3
+
+//checkTorrentTokenUse is like checkSearchTokenUse for a torrent fetched by id. The torrent endpoint does not say whether a token may be used, so the torrent is looked up in a search for its group, artist and year. ErrTokenStatusUnknown is returned if it is not on the first few pages.
+func (w *WhatAPI) checkTorrentTokenUse(ctx context.Context, torrent Torrent) error {
+	options := TorrentSearchOptions{GroupName: torrent.Group.Name, YearFrom: torrent.Group.Year, YearTo: torrent.Group.Year}
+	if artists := torrent.Group.MusicInfo.Artists; len(artists) > 0 {
+		options.ArtistName = artists[0].Name
+	}
+	searched := 0
+	for page, err := range w.TorrentSearchPages(ctx, "", options) {
+		if err != nil {
+			return err
+		}
+		for _, group := range page.Results {
+			if group.GroupID != torrent.Group.ID {
+				continue
+			}
+			for _, result := range group.Torrents {
+				if result.TorrentID == torrent.Torrent.ID {
+					return w.checkSearchTokenUse(ctx, result)
+				}
+			}
+		}
+		if searched++; searched >= tokenSearchPages {
+			break
+		}
+	}
+	return ErrTokenStatusUnknown
+}
+
+//checkSearchTokenUse refuses to spend a freeleech token on a search result the site does not allow one on.
+func (w *WhatAPI) checkSearchTokenUse(ctx context.Context, torrent SearchTorrent) error {
+	if !torrent.CanUseToken {
+		return ErrTokenNotAllowed
+	}
+	return w.checkTokenUse(ctx, torrent.IsFreeleech || torrent.IsPersonalFreeleech || torrent.IsNeutralLeech)
+}
+
+//checkTokenUse refuses to spend a freeleech token on a torrent that is already free or when the current user has none left.
+func (w *WhatAPI) checkTokenUse(ctx context.Context, alreadyFree bool) error {
+	if alreadyFree {
+		return ErrAlreadyFreeleech
+	}
+	tokens, err := w.GetFreeleechTokens(ctx)
+	if err != nil {
+		return err
+	}
+	if tokens < 1 {
+		return ErrNoTokens
+	}
+	return nil
+}
+
+//fetchTorrent downloads and checks a .torrent file without any token checks.
+func (w *WhatAPI) fetchTorrent(ctx context.Context, id int, useToken bool) ([]byte, error) {
+	downloadURL, err := w.CreateDownloadURL(id)
+	if err != nil {
+		return nil, err
+	}
+	w.mu.RLock()
+	generation := w.generation
+	w.mu.RUnlock()
+	var resp *http.Response
+	if useToken {
+		//Spending a token changes state on the site, so the request is sent only once.
+		downloadURL += "&usetoken=1"
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = w.do(req)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resp, err = w.getRetrying(ctx, downloadURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxTorrentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, newAPIError(downloadURL, resp.StatusCode, pageError(data))
+	}
+	if path.Base(resp.Request.URL.Path) == "login.php" {
+		return nil, w.loginRedirect(generation)
+	}
+	if len(data) > MaxTorrentSize {
+		return nil, ErrNotTorrent
+	}
+	if _, err := ParseMetaInfo(data); err != nil {
+		if message := pageError(data); message != "" {
+			return nil, newAPIError(downloadURL, resp.StatusCode, message)
+		}
+		return nil, ErrNotTorrent
+	}
+	return data, nil
+}
+
+//DownloadTorrentTo downloads the .torrent file for the torrent with the provided id into dir like DownloadTorrent and returns the path of the written file. The file is named after the torrent's group and edition, e.g. "Artist - Album (2001) [CD FLAC Lossless].torrent".
+func (w *WhatAPI) DownloadTorrentTo(ctx context.Context, id int, dir string, useToken bool) (string, error) {
+	torrent, err := w.GetTorrent(ctx, id, url.Values{})
+	if err != nil {
+		return "", err
+	}
+	if useToken {
+		if err := w.checkTorrentTokenUse(ctx, torrent); err != nil {
+			return "", err
+		}
+	}
+	data, err := w.fetchTorrent(ctx, id, useToken)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Join(dir, torrentFileName(torrent))
+	tmp, err := os.CreateTemp(dir, ".whatapi-*.torrent")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", err
+	}
+	return name, os.Rename(tmp.Name(), name)
+}
+
+var fileNameReplacer = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+
+//torrentFileName names a .torrent file after its group and edition.
+func torrentFileName(torrent Torrent) string {
+	group, edition := torrent.Group, torrent.Torrent
+	var artists []string
+	for _, artist := range group.MusicInfo.Artists {
+		artists = append(artists, artist.Name)
+	}
+	name := group.Name
+	switch {
+	case len(artists) > 2:
+		name = "Various Artists - " + name
+	case len(artists) > 0:
+		name = strings.Join(artists, " & ") + " - " + name
+	}
+	year := group.Year
+	if edition.Remastered && edition.RemasterYear != 0 {
+		year = edition.RemasterYear
+	}
+	if year != 0 {
+		name += " (" + strconv.Itoa(year) + ")"
+	}
+	var details []string
+	for _, detail := range []string{edition.Media, edition.Format, edition.Encoding} {
+		if detail != "" {
+			details = append(details, detail)
+		}
+	}
+	if len(details) > 0 {
+		name += " [" + strings.Join(details, " ") + "]"
+	}
+	if name == "" {
+		name = strconv.Itoa(edition.ID)
+	}
+	return fileNameReplacer.Replace(name) + ".torrent"
+}
+
+var profileTokens = regexp.MustCompile(`Tokens:\s*(?:<[^>]*>\s*)*([\d,]+)`)
+
+//GetFreeleechTokens retrieves the number of freeleech tokens the current user has left. The JSON API does not report them, so they are read from the user's profile page.
+func (w *WhatAPI) GetFreeleechTokens(ctx context.Context) (int, error) {
+	w.mu.RLock()
+	userID := w.userID
+	w.mu.RUnlock()
+	page, err := w.getAction(ctx, "user.php", url.Values{"id": {strconv.Itoa(userID)}})
+	if err != nil {
+		return 0, err
+	}
+	match := profileTokens.FindSubmatch(page)
+	if match == nil {
+		return 0, ErrRequestFailed
+	}
+	return strconv.Atoi(strings.ReplaceAll(string(match[1]), ",", ""))
+}
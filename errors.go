@@ -0,0 +1,140 @@
+package whatapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	//ErrLoginFailed is returned when the site rejects a login attempt.
+	ErrLoginFailed = errors.New("Login failed")
+	//ErrBadCredentials is returned when the site rejects a username and password. It matches ErrLoginFailed.
+	ErrBadCredentials = fmt.Errorf("%w: username or password incorrect", ErrLoginFailed)
+	//Err2FARequired is returned when the account needs a 2FA code but none was provided. It matches ErrLoginFailed.
+	Err2FARequired = fmt.Errorf("%w: 2FA code required", ErrLoginFailed)
+	//ErrBad2FACode is returned when the site rejects a 2FA code. It matches ErrLoginFailed.
+	ErrBad2FACode = fmt.Errorf("%w: 2FA code incorrect", ErrLoginFailed)
+	//ErrTooManyAttempts is returned when the site refuses logins after too many failed attempts. It matches ErrLoginFailed.
+	ErrTooManyAttempts = fmt.Errorf("%w: too many failed attempts", ErrLoginFailed)
+	//ErrIPBanned is returned when the site has banned the client's IP address. It matches ErrLoginFailed.
+	ErrIPBanned = fmt.Errorf("%w: IP address banned", ErrLoginFailed)
+	//ErrNotLoggedIn is returned when a request needs a session but the client is not logged in.
+	ErrNotLoggedIn = errors.New("Request failed: not logged in")
+	//ErrRequestFailed matches every APIError.
+	ErrRequestFailed = errors.New("Request failed")
+	//ErrBadID matches an APIError for a request whose id parameter the site did not accept.
+	ErrBadID = errors.New("Request failed: bad id parameter")
+	//ErrBadParameters matches an APIError for a request whose parameters the site did not accept.
+	ErrBadParameters = errors.New("Request failed: bad parameters")
+	//ErrSessionExpired is returned when the site session has expired and logging in again was not possible.
+	ErrSessionExpired = errors.New("Request failed: session expired")
+	//ErrCookieSessionRequired is returned when a client logged in with LoginWithToken uses a page action, which the site only accepts with a cookie session.
+	ErrCookieSessionRequired = errors.New("Request failed: page actions need a cookie session, not an API token")
+	//ErrNoSession is returned when there is no saved session to restore.
+	ErrNoSession = errors.New("No saved session")
+	//ErrSessionNotSaved is returned by Login when the login succeeded but the session could not be written to the session store. The client stays logged in.
+	ErrSessionNotSaved = errors.New("Logged in, but the session could not be saved")
+	//ErrNoRecipient is returned when replying to a conversation in which no other user has posted yet.
+	ErrNoRecipient = errors.New("Request failed: conversation has no other participant")
+	//ErrRequestFilled is returned when voting on a request that has already been filled.
+	ErrRequestFilled = errors.New("Request failed: request already filled")
+	//ErrCannotVote is returned when the current user is not allowed to vote on a request.
+	ErrCannotVote = errors.New("Request failed: cannot vote on request")
+	//ErrVoteTooSmall is returned when a bounty is below the request's minimum vote.
+	ErrVoteTooSmall = errors.New("Request failed: bounty below minimum vote")
+	//ErrInsufficientUpload is returned when the current user's upload does not cover a bounty.
+	ErrInsufficientUpload = errors.New("Request failed: not enough upload for bounty")
+	//ErrNotTorrent is returned when a download does not return a valid .torrent file.
+	ErrNotTorrent = errors.New("Request failed: response is not a torrent file")
+	//ErrMetaInfoMismatch is returned when a .torrent file does not match the torrent the site describes.
+	ErrMetaInfoMismatch = errors.New("Torrent file does not match site metadata")
+	//ErrTokenNotAllowed is returned when the site does not allow a freeleech token to be used on a torrent.
+	ErrTokenNotAllowed = errors.New("Request failed: freeleech token cannot be used on this torrent")
+	//ErrTokenStatusUnknown is returned when DownloadTorrent cannot find the torrent in a search to check whether a freeleech token may be used on it.
+	ErrTokenStatusUnknown = errors.New("Request failed: could not find the torrent to check freeleech token use")
+	//ErrAlreadyFreeleech is returned instead of spending a freeleech token on a torrent that is already free.
+	ErrAlreadyFreeleech = errors.New("Request failed: torrent is already freeleech")
+	//ErrNoTokens is returned when the current user has no freeleech tokens left.
+	ErrNoTokens = errors.New("Request failed: no freeleech tokens left")
+	//ErrRateLimited is returned by a fail-fast RateLimiter once the request budget is exhausted.
+	ErrRateLimited = errors.New("Request failed: rate limit exceeded")
+)
+
+//redactedParams lists the request parameters whose values never appear in an APIError.
+var redactedParams = []string{"auth", "authkey", "torrent_pass", "passkey", "password", "token"}
+
+//APIError describes a request the site answered with a non-200 status or a failure response.
+type APIError struct {
+	//StatusCode is the HTTP status code of the response.
+	StatusCode int
+	//Message is the "error" string reported by Gazelle, if any.
+	Message string
+	//Endpoint is the script the request was sent to, e.g. "ajax.php".
+	Endpoint string
+	//Action is the value of the request's action parameter.
+	Action string
+	//Params holds the remaining request parameters, with secrets redacted.
+	Params url.Values
+}
+
+func newAPIError(requestURL string, statusCode int, message string) *APIError {
+	e := &APIError{StatusCode: statusCode, Message: message}
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return e
+	}
+	e.Endpoint = path.Base(u.Path)
+	e.Params = u.Query()
+	e.Action = e.Params.Get("action")
+	e.Params.Del("action")
+	for _, param := range redactedParams {
+		if _, ok := e.Params[param]; ok {
+			e.Params.Set(param, "REDACTED")
+		}
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	target := e.Endpoint
+	if e.Action != "" {
+		target += "?action=" + e.Action
+	}
+	reason := e.Message
+	if e.StatusCode != 200 {
+		reason = "Status Code " + strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode)
+		if e.Message != "" {
+			reason += ": " + e.Message
+		}
+	}
+	if reason == "" {
+		return "Request failed: " + target
+	}
+	return "Request failed: " + target + ": " + reason
+}
+
+//Unwrap lets errors.Is match an APIError against ErrRequestFailed and, where the Gazelle error string allows, a more specific sentinel.
+func (e *APIError) Unwrap() []error {
+	switch strings.ToLower(e.Message) {
+	case "bad id parameter":
+		return []error{ErrRequestFailed, ErrBadID}
+	case "bad parameters":
+		return []error{ErrRequestFailed, ErrBadParameters}
+	}
+	return []error{ErrRequestFailed}
+}
+
+//ValidationError is returned when input is rejected locally, before anything is sent to the site.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return "Invalid " + e.Field + ": " + e.Reason
+}
@@ -0,0 +1,66 @@
+package whatapi
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+//ReplyToThread posts a reply with the provided body to the forum thread with the provided id.
+func (w *WhatAPI) ReplyToThread(ctx context.Context, threadID int, body string) error {
+	params := url.Values{}
+	params.Set("action", "reply")
+	params.Set("thread", strconv.Itoa(threadID))
+	params.Set("body", body)
+	_, err := w.postAction(ctx, "forums.php", params)
+	return err
+}
+
+//EditPost replaces the body of the current user's forum post with the provided id.
+func (w *WhatAPI) EditPost(ctx context.Context, postID int, body string) error {
+	params := url.Values{}
+	params.Set("action", "takeedit")
+	params.Set("post", strconv.Itoa(postID))
+	params.Set("body", body)
+	_, err := w.postAction(ctx, "forums.php", params)
+	return err
+}
+
+//VotePoll votes for an answer in the poll of the forum thread with the provided id. Answers are numbered from 1 in the order of Thread.Poll.Answers.
+func (w *WhatAPI) VotePoll(ctx context.Context, threadID, answer int) error {
+	if answer < 1 {
+		return &ValidationError{"answer", "answers are numbered from 1"}
+	}
+	params := url.Values{}
+	params.Set("action", "poll")
+	params.Set("threadid", strconv.Itoa(threadID))
+	params.Set("vote", strconv.Itoa(answer))
+	_, err := w.postAction(ctx, "forums.php", params)
+	return err
+}
+
+//SubscribeThread subscribes the current user to the forum thread with the provided id.
+func (w *WhatAPI) SubscribeThread(ctx context.Context, threadID int) error {
+	return w.setThreadSubscription(ctx, threadID, true)
+}
+
+//UnsubscribeThread unsubscribes the current user from the forum thread with the provided id.
+func (w *WhatAPI) UnsubscribeThread(ctx context.Context, threadID int) error {
+	return w.setThreadSubscription(ctx, threadID, false)
+}
+
+//setThreadSubscription brings the subscription to a thread into the wanted state. The site only offers a toggle, so the current state is retrieved first.
+func (w *WhatAPI) setThreadSubscription(ctx context.Context, threadID int, subscribed bool) error {
+	thread, err := w.GetThread(ctx, threadID, url.Values{})
+	if err != nil {
+		return err
+	}
+	if thread.Subscribed == subscribed {
+		return nil
+	}
+	params := url.Values{}
+	params.Set("action", "thread_subscribe")
+	params.Set("topicid", strconv.Itoa(threadID))
+	_, err = w.getAction(ctx, "userhistory.php", params)
+	return err
+}
@@ -0,0 +1,3 @@
+module github.com/kdvh/whatapi
+
+go 1.23
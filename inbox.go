@@ -0,0 +1,84 @@
+package whatapi
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+//SendMessage sends a private message with the provided subject and body to the user with the provided id.
+func (w *WhatAPI) SendMessage(ctx context.Context, toUserID int, subject, body string) error {
+	params := url.Values{}
+	params.Set("action", "takecompose")
+	params.Set("toid", strconv.Itoa(toUserID))
+	params.Set("subject", subject)
+	params.Set("body", body)
+	_, err := w.postAction(ctx, "inbox.php", params)
+	return err
+}
+
+//ReplyToConversation replies to the conversation with the provided id. The site needs the other participant's id, so the conversation is retrieved first.
+func (w *WhatAPI) ReplyToConversation(ctx context.Context, convID int, body string) error {
+	conversation, err := w.GetConversation(ctx, convID)
+	if err != nil {
+		return err
+	}
+	w.mu.RLock()
+	userID := w.userID
+	w.mu.RUnlock()
+	toID := 0
+	for _, message := range conversation.Messages {
+		if message.SenderID != userID && message.SenderID != 0 {
+			toID = message.SenderID
+			break
+		}
+	}
+	if toID == 0 {
+		return ErrNoRecipient
+	}
+	params := url.Values{}
+	params.Set("action", "takecompose")
+	params.Set("convid", strconv.Itoa(convID))
+	params.Set("toid", strconv.Itoa(toID))
+	params.Set("body", body)
+	_, err = w.postAction(ctx, "inbox.php", params)
+	return err
+}
+
+//MarkConversationsRead marks the conversations with the provided ids as read.
+func (w *WhatAPI) MarkConversationsRead(ctx context.Context, convIDs ...int) error {
+	return w.massChangeConversations(ctx, "read", convIDs)
+}
+
+//MarkConversationsUnread marks the conversations with the provided ids as unread.
+func (w *WhatAPI) MarkConversationsUnread(ctx context.Context, convIDs ...int) error {
+	return w.massChangeConversations(ctx, "unread", convIDs)
+}
+
+//DeleteConversations deletes the conversations with the provided ids from the current user's mailbox.
+func (w *WhatAPI) DeleteConversations(ctx context.Context, convIDs ...int) error {
+	return w.massChangeConversations(ctx, "delete", convIDs)
+}
+
+//SetConversationSticky sticks the conversation with the provided id to the top of the mailbox, or unsticks it.
+func (w *WhatAPI) SetConversationSticky(ctx context.Context, convID int, sticky bool) error {
+	params := url.Values{}
+	params.Set("action", "takeedit")
+	params.Set("convid", strconv.Itoa(convID))
+	if sticky {
+		params.Set("sticky", "on")
+	}
+	_, err := w.postAction(ctx, "inbox.php", params)
+	return err
+}
+
+func (w *WhatAPI) massChangeConversations(ctx context.Context, change string, convIDs []int) error {
+	params := url.Values{}
+	params.Set("action", "masschange")
+	for _, id := range convIDs {
+		params.Add("messages[]", strconv.Itoa(id))
+	}
+	params.Set(change, "1")
+	_, err := w.postAction(ctx, "inbox.php", params)
+	return err
+}
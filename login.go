@@ -0,0 +1,28 @@
+package whatapi
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+)
+
+//TOTPProvider supplies the current two-factor authentication code when logging in to an account with 2FA enabled.
+type TOTPProvider func(ctx context.Context) (string, error)
+
+//twoFactorChallenge reports whether the site answered a login attempt by asking for a 2FA code.
+func twoFactorChallenge(resp *http.Response, body []byte) bool {
+	return resp.Request.URL.Query().Get("act") == "2fa" || bytes.Contains(body, []byte(`name="2fa"`))
+}
+
+//loginFailure maps the message on a failed login page to the matching error.
+func loginFailure(body []byte) error {
+	switch {
+	case bytes.Contains(body, []byte("IP address has been banned")):
+		return ErrIPBanned
+	case bytes.Contains(body, []byte("You are banned from logging in")):
+		return ErrTooManyAttempts
+	case bytes.Contains(body, []byte("username or password was incorrect")):
+		return ErrBadCredentials
+	}
+	return ErrLoginFailed
+}
@@ -0,0 +1,108 @@
+package whatapi
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"path"
+
+	"github.com/kdvh/whatapi/bencode"
+)
+
+//MetaInfo holds the contents of a .torrent file.
+type MetaInfo struct {
+	//InfoHash is the v1 info hash, the SHA-1 of the bencoded info dictionary.
+	InfoHash    [sha1.Size]byte
+	Name        string
+	PieceLength int64
+	NumPieces   int
+	Private     bool
+	//Source is the tracker-specific source tag that makes the info hash unique to one site.
+	Source string
+	Files  []MetaInfoFile
+	//Announce lists the announce URLs, taken from announce-list if present and from announce otherwise.
+	Announce []string
+}
+
+//MetaInfoFile is a file described by a .torrent file. Path is slash-separated and relative to the torrent's name.
+type MetaInfoFile struct {
+	Path   string
+	Length int64
+}
+
+type rawMetaInfo struct {
+	Announce     string             `bencode:"announce"`
+	AnnounceList [][]string         `bencode:"announce-list"`
+	Info         bencode.RawMessage `bencode:"info"`
+}
+
+type rawInfo struct {
+	Name        string `bencode:"name"`
+	PieceLength int64  `bencode:"piece length"`
+	Pieces      []byte `bencode:"pieces"`
+	Private     int    `bencode:"private"`
+	Source      string `bencode:"source"`
+	Length      int64  `bencode:"length"`
+	Files       []struct {
+		Length int64    `bencode:"length"`
+		Path   []string `bencode:"path"`
+	} `bencode:"files"`
+}
+
+//ParseMetaInfo parses the contents of a .torrent file.
+func ParseMetaInfo(data []byte) (*MetaInfo, error) {
+	raw := rawMetaInfo{}
+	if err := bencode.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw.Info) == 0 {
+		return nil, ErrNotTorrent
+	}
+	info := rawInfo{}
+	if err := bencode.Unmarshal(raw.Info, &info); err != nil {
+		return nil, err
+	}
+	if info.PieceLength <= 0 || len(info.Pieces)%sha1.Size != 0 {
+		return nil, ErrNotTorrent
+	}
+	m := &MetaInfo{
+		InfoHash:    sha1.Sum(raw.Info),
+		Name:        info.Name,
+		PieceLength: info.PieceLength,
+		NumPieces:   len(info.Pieces) / sha1.Size,
+		Private:     info.Private == 1,
+		Source:      info.Source,
+	}
+	if len(info.Files) == 0 {
+		m.Files = []MetaInfoFile{{Path: info.Name, Length: info.Length}}
+	}
+	for _, f := range info.Files {
+		m.Files = append(m.Files, MetaInfoFile{Path: path.Join(f.Path...), Length: f.Length})
+	}
+	for _, tier := range raw.AnnounceList {
+		m.Announce = append(m.Announce, tier...)
+	}
+	if len(m.Announce) == 0 && raw.Announce != "" {
+		m.Announce = []string{raw.Announce}
+	}
+	return m, nil
+}
+
+//Size returns the total length of the torrent's files.
+func (m *MetaInfo) Size() int64 {
+	var size int64
+	for _, f := range m.Files {
+		size += f.Length
+	}
+	return size
+}
+
+//Check compares the torrent with the file count and size the site reports for it.
+func (m *MetaInfo) Check(torrent TorrentType) error {
+	if len(m.Files) != torrent.FileCount {
+		return fmt.Errorf("%w: %d files, site reports %d", ErrMetaInfoMismatch, len(m.Files), torrent.FileCount)
+	}
+	if m.Size() != int64(torrent.Size) {
+		return fmt.Errorf("%w: %d bytes, site reports %d", ErrMetaInfoMismatch, m.Size(), torrent.Size)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package whatapi
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	singleFileInfo = "d6:lengthi5e4:name5:a.txt12:piece lengthi16384e6:pieces20:0123456789abcdefghij7:privatei1e6:source3:OPSe"
+	multiFileInfo  = "d5:filesld6:lengthi3e4:pathl3:cd17:01.flaceed6:lengthi4e4:pathl3:cd27:01.flaceee4:name5:album12:piece lengthi16384e6:pieces20:0123456789abcdefghije"
+)
+
+func TestParseMetaInfoSingleFile(t *testing.T) {
+	m, err := ParseMetaInfo([]byte("d8:announce14:http://tracker4:info" + singleFileInfo + "e"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(m.InfoHash[:]); got != "9bcf9b45ce72ac74ca01a0c610f38c6b0fe63b74" {
+		t.Errorf("InfoHash = %s", got)
+	}
+	if m.Name != "a.txt" || m.PieceLength != 16384 || m.NumPieces != 1 || !m.Private || m.Source != "OPS" {
+		t.Errorf("ParseMetaInfo = %+v", m)
+	}
+	if want := []MetaInfoFile{{Path: "a.txt", Length: 5}}; !reflect.DeepEqual(m.Files, want) {
+		t.Errorf("Files = %+v, want %+v", m.Files, want)
+	}
+	if want := []string{"http://tracker"}; !reflect.DeepEqual(m.Announce, want) {
+		t.Errorf("Announce = %q, want %q", m.Announce, want)
+	}
+}
+
+func TestParseMetaInfoMultiFile(t *testing.T) {
+	data := "d8:announce14:http://tracker13:announce-listll6:http:ael6:http:b6:http:cee4:info" + multiFileInfo + "e"
+	m, err := ParseMetaInfo([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Private || m.Source != "" {
+		t.Errorf("Private = %v, Source = %q, want a public torrent without a source", m.Private, m.Source)
+	}
+	if want := []MetaInfoFile{{Path: "cd1/01.flac", Length: 3}, {Path: "cd2/01.flac", Length: 4}}; !reflect.DeepEqual(m.Files, want) {
+		t.Errorf("Files = %+v, want %+v", m.Files, want)
+	}
+	if m.Size() != 7 {
+		t.Errorf("Size = %d, want 7", m.Size())
+	}
+	if want := []string{"http:a", "http:b", "http:c"}; !reflect.DeepEqual(m.Announce, want) {
+		t.Errorf("Announce = %q, want %q", m.Announce, want)
+	}
+	if err := m.Check(TorrentType{FileCount: 2, Size: 7}); err != nil {
+		t.Errorf("Check of a matching torrent: %v", err)
+	}
+	for _, torrent := range []TorrentType{{FileCount: 3, Size: 7}, {FileCount: 2, Size: 8}} {
+		if err := m.Check(torrent); !errors.Is(err, ErrMetaInfoMismatch) {
+			t.Errorf("Check(%+v) = %v, want ErrMetaInfoMismatch", torrent, err)
+		}
+	}
+}
+
+func TestParseMetaInfoInvalid(t *testing.T) {
+	for _, data := range []string{
+		"d8:announce14:http://trackere",
+		"d4:info" + strings.Replace(singleFileInfo, "i16384e", "i0e", 1) + "e",
+		"d4:info" + strings.Replace(singleFileInfo, "6:pieces20:0123456789abcdefghij", "6:pieces3:abc", 1) + "e",
+	} {
+		if _, err := ParseMetaInfo([]byte(data)); !errors.Is(err, ErrNotTorrent) {
+			t.Errorf("ParseMetaInfo(%q) = %v, want ErrNotTorrent", data, err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package whatapi
+
+import (
+	"context"
+	"html"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//NotificationFilter is a torrent notification filter. List fields are matched as alternatives; empty lists match anything.
+type NotificationFilter struct {
+	//ID identifies an existing filter; it is 0 for a filter that has not been added yet.
+	ID                 int
+	Label              string
+	Artists            []string
+	NewGroupsOnly      bool
+	Tags               []string
+	ExcludedTags       []string
+	Categories         []string
+	ReleaseTypes       []string
+	Formats            []string
+	Encodings          []string
+	Media              []string
+	FromYear           int
+	ToYear             int
+	Users              []string
+	ExcludeVanityHouse bool
+}
+
+var (
+	formInput    = regexp.MustCompile(`(?s)<input\b[^>]*>`)
+	formTextarea = regexp.MustCompile(`(?s)<textarea\b([^>]*)>(.*?)</textarea>`)
+	tagAttribute = regexp.MustCompile(`(\w+)="([^"]*)"`)
+	filterIDName = regexp.MustCompile(`^id(\d+)$`)
+)
+
+//GetNotificationFilters retrieves the current user's notification filters. The site has no JSON endpoint for them, so they are read from the filter forms on its notification settings page.
+func (w *WhatAPI) GetNotificationFilters(ctx context.Context) ([]NotificationFilter, error) {
+	params := url.Values{}
+	params.Set("action", "notify")
+	body, err := w.getAction(ctx, "user.php", params)
+	if err != nil {
+		return nil, err
+	}
+	fields := parseFormFields(body)
+	var indexes []int
+	for name := range fields {
+		if match := filterIDName.FindStringSubmatch(name); match != nil {
+			index, _ := strconv.Atoi(match[1])
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+	filters := make([]NotificationFilter, 0, len(indexes))
+	for _, index := range indexes {
+		i := strconv.Itoa(index)
+		get := func(name string) string {
+			if values := fields[name+i]; len(values) > 0 {
+				return values[0]
+			}
+			return ""
+		}
+		list := func(name string) []string {
+			if values, ok := fields[name+i+"[]"]; ok {
+				return values
+			}
+			return splitList(get(name))
+		}
+		id, _ := strconv.Atoi(get("id"))
+		fromYear, _ := strconv.Atoi(get("fromyear"))
+		toYear, _ := strconv.Atoi(get("toyear"))
+		filters = append(filters, NotificationFilter{
+			ID:                 id,
+			Label:              get("label"),
+			Artists:            list("artists"),
+			NewGroupsOnly:      get("newgroupsonly") != "",
+			Tags:               list("tags"),
+			ExcludedTags:       list("nottags"),
+			Categories:         list("categories"),
+			ReleaseTypes:       list("releasetypes"),
+			Formats:            list("formats"),
+			Encodings:          list("bitrates"),
+			Media:              list("media"),
+			FromYear:           fromYear,
+			ToYear:             toYear,
+			Users:              list("users"),
+			ExcludeVanityHouse: get("excludevh") != "",
+		})
+	}
+	return filters, nil
+}
+
+//AddNotificationFilter adds a new notification filter for the current user. The filter's ID is ignored.
+func (w *WhatAPI) AddNotificationFilter(ctx context.Context, filter NotificationFilter) error {
+	filter.ID = 0
+	return w.saveNotificationFilter(ctx, filter)
+}
+
+//UpdateNotificationFilter replaces the notification filter with the filter's ID.
+func (w *WhatAPI) UpdateNotificationFilter(ctx context.Context, filter NotificationFilter) error {
+	if filter.ID == 0 {
+		return &ValidationError{"ID", "required to update a filter"}
+	}
+	return w.saveNotificationFilter(ctx, filter)
+}
+
+//DeleteNotificationFilter deletes the notification filter with the provided id.
+func (w *WhatAPI) DeleteNotificationFilter(ctx context.Context, id int) error {
+	params := url.Values{}
+	params.Set("action", "notify_delete")
+	params.Set("id", strconv.Itoa(id))
+	_, err := w.getAction(ctx, "user.php", params)
+	return err
+}
+
+//MarkNotificationsRead marks all of the current user's torrent notifications as read.
+func (w *WhatAPI) MarkNotificationsRead(ctx context.Context) error {
+	return w.notificationAction(ctx, "notify_catchup", nil)
+}
+
+//ClearNotifications removes all of the current user's torrent notifications.
+func (w *WhatAPI) ClearNotifications(ctx context.Context) error {
+	return w.notificationAction(ctx, "notify_clear", nil)
+}
+
+//ClearNotification removes the current user's notification for the torrent with the provided id.
+func (w *WhatAPI) ClearNotification(ctx context.Context, torrentID int) error {
+	return w.notificationAction(ctx, "notify_clear_item", url.Values{"torrentid": {strconv.Itoa(torrentID)}})
+}
+
+//ClearFilterNotifications removes the current user's notifications produced by the filter with the provided id.
+func (w *WhatAPI) ClearFilterNotifications(ctx context.Context, filterID int) error {
+	return w.notificationAction(ctx, "notify_clear_filter", url.Values{"filterid": {strconv.Itoa(filterID)}})
+}
+
+func (w *WhatAPI) notificationAction(ctx context.Context, action string, params url.Values) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("action", action)
+	_, err := w.getAction(ctx, "torrents.php", params)
+	return err
+}
+
+//saveNotificationFilter submits filter the way the site's filter form does. Without an ID the site adds a new filter.
+func (w *WhatAPI) saveNotificationFilter(ctx context.Context, filter NotificationFilter) error {
+	if filter.FromYear != 0 && filter.ToYear != 0 && filter.FromYear > filter.ToYear {
+		return &ValidationError{"FromYear", "after ToYear"}
+	}
+	const i = "1"
+	params := url.Values{}
+	params.Set("action", "notify_handle")
+	params.Set("formid", i)
+	if filter.ID != 0 {
+		params.Set("id"+i, strconv.Itoa(filter.ID))
+	}
+	params.Set("label"+i, filter.Label)
+	params.Set("artists"+i, strings.Join(filter.Artists, ", "))
+	params.Set("tags"+i, strings.Join(filter.Tags, ", "))
+	params.Set("nottags"+i, strings.Join(filter.ExcludedTags, ", "))
+	params.Set("users"+i, strings.Join(filter.Users, ", "))
+	if filter.FromYear != 0 {
+		params.Set("fromyear"+i, strconv.Itoa(filter.FromYear))
+	}
+	if filter.ToYear != 0 {
+		params.Set("toyear"+i, strconv.Itoa(filter.ToYear))
+	}
+	if filter.NewGroupsOnly {
+		params.Set("newgroupsonly"+i, "1")
+	}
+	if filter.ExcludeVanityHouse {
+		params.Set("excludevh"+i, "1")
+	}
+	for name, values := range map[string][]string{
+		"categories":   filter.Categories,
+		"releasetypes": filter.ReleaseTypes,
+		"formats":      filter.Formats,
+		"bitrates":     filter.Encodings,
+		"media":        filter.Media,
+	} {
+		for _, value := range values {
+			params.Add(name+i+"[]", value)
+		}
+	}
+	_, err := w.postAction(ctx, "user.php", params)
+	return err
+}
+
+//parseFormFields collects the values a browser would submit for the input and textarea fields in an HTML page.
+func parseFormFields(page []byte) map[string][]string {
+	fields := map[string][]string{}
+	for _, tag := range formInput.FindAll(page, -1) {
+		attrs := map[string]string{}
+		for _, attr := range tagAttribute.FindAllSubmatch(tag, -1) {
+			attrs[strings.ToLower(string(attr[1]))] = html.UnescapeString(string(attr[2]))
+		}
+		name := attrs["name"]
+		if name == "" {
+			continue
+		}
+		switch strings.ToLower(attrs["type"]) {
+		case "checkbox", "radio":
+			if !strings.Contains(string(tag), "checked") {
+				continue
+			}
+			if _, ok := attrs["value"]; !ok {
+				attrs["value"] = "on"
+			}
+		case "submit", "button":
+			continue
+		}
+		fields[name] = append(fields[name], attrs["value"])
+	}
+	for _, match := range formTextarea.FindAllSubmatch(page, -1) {
+		for _, attr := range tagAttribute.FindAllSubmatch(match[1], -1) {
+			if strings.ToLower(string(attr[1])) == "name" {
+				name := string(attr[2])
+				fields[name] = append(fields[name], html.UnescapeString(string(match[2])))
+			}
+		}
+	}
+	return fields
+}
+
+//splitList splits a comma separated list as entered in the site's forms.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
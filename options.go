@@ -0,0 +1,142 @@
+package whatapi
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+//Option configures a WhatAPI client created by NewWhatAPI.
+type Option func(*WhatAPI) error
+
+//WithRateLimiter sets the limiter every request goes through. Passing nil disables rate limiting.
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(w *WhatAPI) error {
+		w.limiter = l
+		return nil
+	}
+}
+
+//defaultRateLimiter matches Gazelle's ajax.php quota of 5 requests per 10 seconds.
+func defaultRateLimiter() *RateLimiter {
+	return NewRateLimiter(5, 10*time.Second, false)
+}
+
+//WithRetryPolicy sets the policy used to retry ajax.php requests after transient failures.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(w *WhatAPI) error {
+		w.retry = p
+		return nil
+	}
+}
+
+//WithHTTPClient makes the client send its requests through a copy of c. If c has no cookie jar, the client's own jar is kept so that Login still works. WithTimeout, WithTransport and WithProxy are applied to the copy whether they are passed before or after WithHTTPClient.
+func WithHTTPClient(c *http.Client) Option {
+	return func(w *WhatAPI) error {
+		if c == nil {
+			return errors.New("WithHTTPClient: nil client")
+		}
+		client := *c
+		if client.Jar == nil {
+			client.Jar = w.client.Jar
+		}
+		w.client = &client
+		return nil
+	}
+}
+
+//clientOption defers a change to the HTTP client until every option has run.
+func clientOption(apply func(*http.Client) error) Option {
+	return func(w *WhatAPI) error {
+		w.clientOptions = append(w.clientOptions, apply)
+		return nil
+	}
+}
+
+//WithTimeout sets the time limit for each HTTP request, including reading the response body.
+func WithTimeout(d time.Duration) Option {
+	return clientOption(func(c *http.Client) error {
+		c.Timeout = d
+		return nil
+	})
+}
+
+//WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(w *WhatAPI) error {
+		w.userAgent = userAgent
+		return nil
+	}
+}
+
+//WithTransport sets the round tripper used to send HTTP requests.
+func WithTransport(rt http.RoundTripper) Option {
+	return clientOption(func(c *http.Client) error {
+		c.Transport = rt
+		return nil
+	})
+}
+
+//WithProxy routes every request through the proxy at proxyURL. It requires the client's transport to be an *http.Transport, which is the case unless WithTransport set another one.
+func WithProxy(proxyURL string) Option {
+	return clientOption(func(c *http.Client) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return err
+		}
+		var transport *http.Transport
+		switch rt := c.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = rt.Clone()
+		default:
+			return errors.New("WithProxy: transport is not an *http.Transport")
+		}
+		transport.Proxy = http.ProxyURL(u)
+		c.Transport = transport
+		return nil
+	})
+}
+
+//WithSessionStore makes Login save the session to store, so that RestoreSession can resume it later without logging in again.
+func WithSessionStore(store SessionStore) Option {
+	return func(w *WhatAPI) error {
+		w.sessions = store
+		return nil
+	}
+}
+
+//WithCredentials lets the client log in again with credentials from provider when its session expires, replaying the request that noticed the expiry.
+func WithCredentials(provider CredentialProvider) Option {
+	return func(w *WhatAPI) error {
+		w.credentials = provider
+		return nil
+	}
+}
+
+//WithTOTP sets the provider Login asks for a 2FA code when the account has two-factor authentication enabled.
+func WithTOTP(provider TOTPProvider) Option {
+	return func(w *WhatAPI) error {
+		w.totp = provider
+		return nil
+	}
+}
+
+//WithDebug makes the client log the method and URL of every request it sends to out. The URLs may contain the authkey and passkey.
+func WithDebug(out io.Writer) Option {
+	return func(w *WhatAPI) error {
+		w.debug = out
+		return nil
+	}
+}
+
+//WithTagAliases sets the aliases used when normalizing tag names, mapping a normalized tag to the tag the site uses instead, e.g. "hiphop" to "hip.hop".
+func WithTagAliases(aliases map[string]string) Option {
+	return func(w *WhatAPI) error {
+		w.tagAliases = aliases
+		return nil
+	}
+}
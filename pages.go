@@ -0,0 +1,207 @@
+package whatapi
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+//pages walks a paged endpoint from the start page to the last page the site reports. fetch returns a page along with the total number of pages. Iteration stops after the first error.
+func pages[T any](ctx context.Context, start int, fetch func(page int) (T, int, error)) iter.Seq2[T, error] {
+	if start < 1 {
+		start = 1
+	}
+	return func(yield func(T, error) bool) {
+		for page := start; ; page++ {
+			if err := ctx.Err(); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			response, last, err := fetch(page)
+			if err != nil {
+				yield(response, err)
+				return
+			}
+			if !yield(response, nil) || page >= last {
+				return
+			}
+		}
+	}
+}
+
+//withPage returns a copy of params with the page set.
+func withPage(params url.Values, page int) url.Values {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("page", strconv.Itoa(page))
+	return query
+}
+
+//MailboxPages iterates over the pages of the current user's mailbox, starting from the provided page.
+func (w *WhatAPI) MailboxPages(ctx context.Context, params url.Values, page int) iter.Seq2[Mailbox, error] {
+	return pages(ctx, page, func(page int) (Mailbox, int, error) {
+		mailbox, err := w.GetMailbox(ctx, withPage(params, page))
+		return mailbox, mailbox.Pages, err
+	})
+}
+
+//NotificationPages iterates over the pages of the current user's torrent notifications, starting from the provided page.
+func (w *WhatAPI) NotificationPages(ctx context.Context, params url.Values, page int) iter.Seq2[Notifications, error] {
+	return pages(ctx, page, func(page int) (Notifications, int, error) {
+		notifications, err := w.GetNotifications(ctx, withPage(params, page))
+		return notifications, notifications.Pages, err
+	})
+}
+
+//ForumPages iterates over the pages of threads in a forum, starting from the provided page.
+func (w *WhatAPI) ForumPages(ctx context.Context, id, page int) iter.Seq2[Forum, error] {
+	return pages(ctx, page, func(page int) (Forum, int, error) {
+		forum, err := w.GetForum(ctx, id, withPage(nil, page))
+		return forum, forum.Pages, err
+	})
+}
+
+//ThreadPages iterates over the pages of posts in a forum thread, starting from the provided page.
+func (w *WhatAPI) ThreadPages(ctx context.Context, id, page int) iter.Seq2[Thread, error] {
+	return pages(ctx, page, func(page int) (Thread, int, error) {
+		thread, err := w.GetThread(ctx, id, withPage(nil, page))
+		return thread, thread.Pages, err
+	})
+}
+
+//CollagePages iterates over the pages of torrent groups in a collage, starting from the provided page.
+func (w *WhatAPI) CollagePages(ctx context.Context, id, page int) iter.Seq2[Collage, error] {
+	return func(yield func(Collage, error) bool) {
+		seen := 0
+		collages := pages(ctx, page, func(page int) (Collage, int, error) {
+			collage, err := w.GetCollage(ctx, id, withPage(nil, page))
+			seen += len(collage.TorrentGroups)
+			return collage, collageLastPage(collage, page, seen), err
+		})
+		for collage, err := range collages {
+			if !yield(collage, err) {
+				return
+			}
+		}
+	}
+}
+
+//collageLastPage works out when to stop walking a collage. The site does not report a page count for collages, so the walk continues until a page ends with the collage's last group, or until as many groups have been seen as the collage holds in case the site never returns that group.
+func collageLastPage(collage Collage, page, seen int) int {
+	groups, ids := collage.TorrentGroups, collage.TorrentGroupIDList
+	if len(groups) == 0 || len(ids) == 0 || seen >= len(ids) || groups[len(groups)-1].ID == ids[len(ids)-1] {
+		return page
+	}
+	return page + 1
+}
+
+//CollageGroups iterates over the torrent groups of a collage, fetching one page at a time starting from the provided page. Iteration stops after the first error.
+func (w *WhatAPI) CollageGroups(ctx context.Context, id, page int) iter.Seq2[CollageGroup, error] {
+	return func(yield func(CollageGroup, error) bool) {
+		for collage, err := range w.CollagePages(ctx, id, page) {
+			if err != nil {
+				yield(CollageGroup{}, err)
+				return
+			}
+			for _, group := range collage.TorrentGroups {
+				if !yield(group, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+//TorrentCommentPages iterates over the pages of comments on a torrent group, starting from the provided page.
+func (w *WhatAPI) TorrentCommentPages(ctx context.Context, groupID, page int) iter.Seq2[Comments, error] {
+	return pages(ctx, page, func(page int) (Comments, int, error) {
+		comments, err := w.GetTorrentComments(ctx, groupID, withPage(nil, page))
+		return comments, comments.Pages, err
+	})
+}
+
+//ArtistCommentPages iterates over the pages of comments on an artist, starting from the provided page.
+func (w *WhatAPI) ArtistCommentPages(ctx context.Context, artistID, page int) iter.Seq2[Comments, error] {
+	return pages(ctx, page, func(page int) (Comments, int, error) {
+		comments, err := w.GetArtistComments(ctx, artistID, page)
+		return comments, comments.Pages, err
+	})
+}
+
+//TorrentSearchPages iterates over the pages of a torrent search, starting from options.Page.
+func (w *WhatAPI) TorrentSearchPages(ctx context.Context, searchStr string, options TorrentSearchOptions) iter.Seq2[TorrentSearch, error] {
+	return pages(ctx, options.Page, func(page int) (TorrentSearch, int, error) {
+		options.Page = page
+		torrentSearch, err := w.SearchTorrents(ctx, searchStr, options)
+		return torrentSearch, torrentSearch.Pages, err
+	})
+}
+
+//RequestSearchPages iterates over the pages of a request search, starting from options.Page.
+func (w *WhatAPI) RequestSearchPages(ctx context.Context, searchStr string, options RequestSearchOptions) iter.Seq2[RequestsSearch, error] {
+	return pages(ctx, options.Page, func(page int) (RequestsSearch, int, error) {
+		options.Page = page
+		requestsSearch, err := w.SearchRequests(ctx, searchStr, options)
+		return requestsSearch, requestsSearch.Pages, err
+	})
+}
+
+//UserSearchPages iterates over the pages of a user search, starting from options.Page.
+func (w *WhatAPI) UserSearchPages(ctx context.Context, searchStr string, options UserSearchOptions) iter.Seq2[UserSearch, error] {
+	return pages(ctx, options.Page, func(page int) (UserSearch, int, error) {
+		options.Page = page
+		userSearch, err := w.SearchUsers(ctx, searchStr, options)
+		return userSearch, userSearch.Pages, err
+	})
+}
+
+//Pager steps through the pages of an iterator one call at a time, for callers that cannot use range-over-func. Pages are still fetched lazily and no channels are involved.
+type Pager[T any] struct {
+	next func() (T, error, bool)
+	stop func()
+	page T
+	err  error
+}
+
+//NewPager returns a Pager over pages, e.g. one returned by MailboxPages or CollageGroups. Close must be called if the pager is abandoned before Next returns false.
+func NewPager[T any](pages iter.Seq2[T, error]) *Pager[T] {
+	next, stop := iter.Pull2(pages)
+	return &Pager[T]{next: next, stop: stop}
+}
+
+//Next fetches the next page. It returns false when there are no more pages or an error occurred.
+func (p *Pager[T]) Next() bool {
+	if p.err != nil {
+		return false
+	}
+	page, err, ok := p.next()
+	if !ok {
+		return false
+	}
+	if err != nil {
+		p.err = err
+		p.stop()
+		return false
+	}
+	p.page = page
+	return true
+}
+
+//Page returns the page fetched by the last call to Next.
+func (p *Pager[T]) Page() T {
+	return p.page
+}
+
+//Err returns the error that stopped the pager, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+//Close stops the pager; no further pages are fetched.
+func (p *Pager[T]) Close() {
+	p.stop()
+}
@@ -0,0 +1,28 @@
+package whatapi
+
+import "testing"
+
+func TestCollageLastPage(t *testing.T) {
+	ids := []int{1, 2, 3, 4}
+	for _, test := range []struct {
+		name   string
+		groups []int
+		page   int
+		seen   int
+		want   int
+	}{
+		{"more to come", []int{1, 2}, 1, 2, 2},
+		{"ends with the last group", []int{3, 4}, 2, 4, 2},
+		{"empty page", nil, 3, 4, 3},
+		{"all groups seen", []int{3, 2}, 2, 4, 2},
+		{"site repeats a page", []int{1, 2}, 5, 10, 5},
+	} {
+		collage := Collage{TorrentGroupIDList: ids}
+		for _, id := range test.groups {
+			collage.TorrentGroups = append(collage.TorrentGroups, CollageGroup{ID: id})
+		}
+		if got := collageLastPage(collage, test.page, test.seen); got != test.want {
+			t.Errorf("%s: collageLastPage = %d, want %d", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package whatapi
+
+type Collage struct {
+	ID                  int            `json:"id"`
+	Name                string         `json:"name"`
+	Description         string         `json:"description"`
+	CreatorID           int            `json:"creatorID"`
+	Deleted             bool           `json:"deleted"`
+	CollageCategoryID   int            `json:"collageCategoryID"`
+	CollageCategoryName string         `json:"collageCategoryName"`
+	Locked              bool           `json:"locked"`
+	MaxGroups           int            `json:"maxGroups"`
+	MaxGroupsPerUser    int            `json:"maxGroupsPerUser"`
+	HasBookmarked       bool           `json:"hasBookmarked"`
+	SubscriberCount     int            `json:"subscriberCount"`
+	TorrentGroupIDList  []int          `json:"torrentGroupIDList"`
+	TorrentGroups       []CollageGroup `json:"torrentgroups"`
+}
+
+type CollageGroup struct {
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	Year            int    `json:"year"`
+	CategoryID      int    `json:"categoryId"`
+	RecordLabel     string `json:"recordLabel"`
+	CatalogueNumber string `json:"catalogueNumber"`
+	VanityHouse     bool   `json:"vanityHouse"`
+	TagList         string `json:"tagList"`
+	ReleaseType     int    `json:"releaseType"`
+	WikiImage       string `json:"wikiImage"`
+	MusicInfo       struct {
+		Composers []string `json:"composers"`
+		DJ        []string `json:"dj"`
+		Artists   []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"artists"`
+		With []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"with"`
+		Conductor []string `json:"conductor"`
+		RemixedBy []string `json:"remixedBy"`
+		Producer  []string `json:"producer"`
+	} `json:"musicInfo"`
+	Torrents []TorrentType `json:"torrents"`
+}
@@ -0,0 +1,18 @@
+package whatapi
+
+type Comments struct {
+	Page     int       `json:"page"`
+	Pages    int       `json:"pages"`
+	Comments []Comment `json:"comments"`
+}
+
+type Comment struct {
+	PostID         int        `json:"postId"`
+	AddedTime      string     `json:"addedTime"`
+	BbBody         string     `json:"bbBody"`
+	Body           string     `json:"body"`
+	EditedUserID   int        `json:"editedUserId"`
+	EditedTime     string     `json:"editedTime"`
+	EditedUsername string     `json:"editedUsername"`
+	Author         PostAuthor `json:"userinfo"`
+}
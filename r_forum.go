@@ -74,27 +74,29 @@ type Thread struct {
 		} `json:"answers"`
 	} `json:"poll"`
 	Posts []struct {
-		PostID         int    `json:"postId"`
-		AddedTime      string `json:"addedTime"`
-		BbBody         string `json:"bbBody"`
-		Body           string `json:"body"`
-		EditedUserID   int    `json:"editedUserId"`
-		EditedTime     string `json:"editedTime"`
-		EditedUsername string `json:"editedUsername"`
-		Author         struct {
-			AuthorID   int      `json:"authorId"`
-			AuthorName string   `json:"authorName"`
-			Paranoia   []string `json:"paranoia"`
-			Artist     bool     `json:"artist"`
-			Donor      bool     `json:"donor"`
-			Warned     bool     `json:"warned"`
-			Avatar     string   `json:"avatar"`
-			Enabled    bool     `json:"enabled"`
-			UserTitle  string   `json:"userTitle"`
-		} `json:"author"`
+		PostID         int        `json:"postId"`
+		AddedTime      string     `json:"addedTime"`
+		BbBody         string     `json:"bbBody"`
+		Body           string     `json:"body"`
+		EditedUserID   int        `json:"editedUserId"`
+		EditedTime     string     `json:"editedTime"`
+		EditedUsername string     `json:"editedUsername"`
+		Author         PostAuthor `json:"author"`
 	} `json:"posts"`
 }
 
+type PostAuthor struct {
+	AuthorID   int      `json:"authorId"`
+	AuthorName string   `json:"authorName"`
+	Paranoia   []string `json:"paranoia"`
+	Artist     bool     `json:"artist"`
+	Donor      bool     `json:"donor"`
+	Warned     bool     `json:"warned"`
+	Avatar     string   `json:"avatar"`
+	Enabled    bool     `json:"enabled"`
+	UserTitle  string   `json:"userTitle"`
+}
+
 type Subscriptions struct {
 	Threads []struct {
 		ForumID     int    `json:"forumId"`
@@ -37,7 +37,7 @@ type Request struct {
 		Conductor []string `json:"conductor"`
 		RemixedBy []string `json:"remixedBy"`
 		Producer  []string `json:"producer"`
-	} `json"musicInfo"`
+	} `json:"musicInfo"`
 	CatalogueNumber string   `json:"catalogueNumber"`
 	ReleaseType     int      `json:"releaseType"`
 	ReleaseName     string   `json:"releaseName"`
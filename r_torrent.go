@@ -32,8 +32,8 @@ type GroupType struct {
 		Conductor []string `json:"conductor"`
 		RemixedBy []string `json:"remixedBy"`
 		Producer  []string `json:"producer"`
-	} `json"musicInfo"`
-	Tags []string `json"tags"`
+	} `json:"musicInfo"`
+	Tags []string `json:"tags"`
 }
 
 type TorrentType struct {
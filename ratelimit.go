@@ -0,0 +1,76 @@
+package whatapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//RateLimiter limits how often a client sends requests using a sliding window: at most the given number of requests are sent in any period, so bursts never exceed the site's quota. It is safe for concurrent use, so one limiter may be shared by several clients logged in as the same user.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests int
+	period   time.Duration
+	//sent holds the send times of the requests in the current window, oldest first. Times in the future belong to callers still waiting in Wait.
+	sent     []time.Time
+	failFast bool
+}
+
+//NewRateLimiter creates a limiter allowing the given number of requests per period. When failFast is set, Wait returns ErrRateLimited instead of blocking once the budget is exhausted.
+func NewRateLimiter(requests int, period time.Duration, failFast bool) *RateLimiter {
+	if requests < 1 {
+		requests = 1
+	}
+	return &RateLimiter{
+		requests: requests,
+		period:   period,
+		sent:     make([]time.Time, 0, requests),
+		failFast: failFast,
+	}
+}
+
+//Wait reserves a slot in the window, blocking until the request may be sent or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	expired := 0
+	for expired < len(l.sent) && !l.sent[expired].Add(l.period).After(now) {
+		expired++
+	}
+	l.sent = append(l.sent[:0], l.sent[expired:]...)
+	at := now
+	if len(l.sent) >= l.requests {
+		if l.failFast {
+			l.mu.Unlock()
+			return ErrRateLimited
+		}
+		at = l.sent[len(l.sent)-l.requests].Add(l.period)
+	}
+	l.sent = append(l.sent, at)
+	l.mu.Unlock()
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel(at)
+		return ctx.Err()
+	}
+}
+
+//cancel gives back the slot reserved for at by a caller that stopped waiting.
+func (l *RateLimiter) cancel(at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := len(l.sent) - 1; i >= 0; i-- {
+		if l.sent[i].Equal(at) {
+			l.sent = append(l.sent[:i], l.sent[i+1:]...)
+			return
+		}
+	}
+}
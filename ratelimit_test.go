@@ -0,0 +1,63 @@
+package whatapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+//allowed counts how many of n immediate calls to Wait a fail-fast limiter lets through.
+func allowed(l *RateLimiter, n int) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		if l.Wait(context.Background()) == nil {
+			count++
+		}
+	}
+	return count
+}
+
+func TestRateLimiterWindow(t *testing.T) {
+	const period = 100 * time.Millisecond
+	l := NewRateLimiter(3, period, true)
+	if got := allowed(l, 10); got != 3 {
+		t.Fatalf("first window allowed %d requests, want 3", got)
+	}
+	if err := l.Wait(context.Background()); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Wait = %v, want ErrRateLimited", err)
+	}
+	time.Sleep(period)
+	if got := allowed(l, 10); got != 3 {
+		t.Fatalf("second window allowed %d requests, want 3", got)
+	}
+}
+
+func TestRateLimiterBlocks(t *testing.T) {
+	const period = 50 * time.Millisecond
+	l := NewRateLimiter(2, period, false)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 2*period {
+		t.Fatalf("5 requests at 2 per %v took %v, want at least %v", period, elapsed, 2*period)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := NewRateLimiter(1, time.Hour, false)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, want context.DeadlineExceeded", err)
+	}
+	if len(l.sent) != 1 {
+		t.Fatalf("cancelled Wait left %d slots reserved, want 1", len(l.sent))
+	}
+}
@@ -0,0 +1,122 @@
+package whatapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//VoteOnRequest adds the provided bounty in bytes to the request with the provided id. The vote is checked against the request's MinimumVote and the current user's upload before it is sent.
+func (w *WhatAPI) VoteOnRequest(ctx context.Context, id int, bounty int64) error {
+	request, err := w.GetRequest(ctx, id, url.Values{})
+	if err != nil {
+		return err
+	}
+	switch {
+	case request.IsFilled:
+		return ErrRequestFilled
+	case !request.CanVote:
+		return ErrCannotVote
+	case bounty < int64(request.MinimumVote):
+		return ErrVoteTooSmall
+	}
+	account, err := w.GetAccount(ctx)
+	if err != nil {
+		return err
+	}
+	if account.UserStats.Uploaded < bounty {
+		return ErrInsufficientUpload
+	}
+	params := url.Values{}
+	params.Set("action", "takevote")
+	params.Set("id", strconv.Itoa(id))
+	params.Set("amount", strconv.FormatInt(bounty, 10))
+	body, err := w.getAction(ctx, "requests.php", params)
+	if err != nil {
+		return err
+	}
+	switch string(bytes.TrimSpace(body)) {
+	case "bankrupt":
+		return ErrInsufficientUpload
+	case "missing":
+		return ErrBadID
+	}
+	return nil
+}
+
+//FillRejection classifies why the site refused to fill a request.
+type FillRejection int
+
+const (
+	//FillRejectedOther is any reason not covered below.
+	FillRejectedOther FillRejection = iota
+	//FillRejectedAlreadyFilled means the request has been filled already.
+	FillRejectedAlreadyFilled
+	//FillRejectedBadTorrent means the torrent does not exist.
+	FillRejectedBadTorrent
+	//FillRejectedTooOld means the torrent was uploaded before the request was made.
+	FillRejectedTooOld
+	//FillRejectedMismatch means the torrent does not match the requested format, bitrate, media or log/cue requirements.
+	FillRejectedMismatch
+)
+
+//FillError is returned by FillRequest when the site refuses the fill. It wraps the underlying APIError.
+type FillError struct {
+	Reason FillRejection
+	Err    *APIError
+}
+
+func (e *FillError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FillError) Unwrap() error {
+	return e.Err
+}
+
+//FillRequest fills the request with the provided id using the torrent with the provided id. If the site refuses the fill, the error is a *FillError.
+func (w *WhatAPI) FillRequest(ctx context.Context, id, torrentID int) error {
+	params := url.Values{}
+	params.Set("action", "takefill")
+	params.Set("requestid", strconv.Itoa(id))
+	//take_fill.php only reads torrentid from the query string; in a POST it wants a link to the torrent instead.
+	params.Set("link", w.baseURL+"torrents.php?torrentid="+strconv.Itoa(torrentID))
+	_, err := w.postAction(ctx, "requests.php", params)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Message != "" {
+		return &FillError{Reason: fillRejection(apiErr.Message), Err: apiErr}
+	}
+	return err
+}
+
+//fillRejections maps phrases from take_fill.php's error messages to the rejection they signal.
+var fillRejections = []struct {
+	phrase string
+	reason FillRejection
+}{
+	{"already been filled", FillRejectedAlreadyFilled},
+	{"valid torrent link", FillRejectedBadTorrent},
+	{"invalid torrent", FillRejectedBadTorrent},
+	{"torrent does not exist", FillRejectedBadTorrent},
+	{"uploaded before the request", FillRejectedTooOld},
+	{"not an allowed format", FillRejectedMismatch},
+	{"not an allowed bitrate", FillRejectedMismatch},
+	{"not allowed media", FillRejectedMismatch},
+	{"not an allowed media", FillRejectedMismatch},
+	{"requires a log", FillRejectedMismatch},
+	{"requires a cue", FillRejectedMismatch},
+	{"log score", FillRejectedMismatch},
+}
+
+func fillRejection(message string) FillRejection {
+	message = strings.ToLower(message)
+	for _, rejection := range fillRejections {
+		if strings.Contains(message, rejection.phrase) {
+			return rejection.reason
+		}
+	}
+	return FillRejectedOther
+}
@@ -0,0 +1,25 @@
+package whatapi
+
+import "testing"
+
+func TestFillRejection(t *testing.T) {
+	for _, test := range []struct {
+		message string
+		want    FillRejection
+	}{
+		{"This request has already been filled.", FillRejectedAlreadyFilled},
+		{"Your link didn't seem to be a valid torrent link", FillRejectedBadTorrent},
+		{"This torrent was uploaded before the request was made.", FillRejectedTooOld},
+		{"MP3 is not an allowed format for this request.", FillRejectedMismatch},
+		{"V2 (VBR) is not an allowed bitrate for this request.", FillRejectedMismatch},
+		{"Vinyl is not allowed media for this request.", FillRejectedMismatch},
+		{"This request requires a log.", FillRejectedMismatch},
+		{"This request requires a cue.", FillRejectedMismatch},
+		{"The catalogue number does not match.", FillRejectedOther},
+		{"You must be logged in to fill requests.", FillRejectedOther},
+	} {
+		if got := fillRejection(test.message); got != test.want {
+			t.Errorf("fillRejection(%q) = %d, want %d", test.message, got, test.want)
+		}
+	}
+}
@@ -30,6 +30,18 @@ type CategoriesResponse struct {
 	Response Categories `json:"response"`
 }
 
+type CollageResponse struct {
+	Status   string  `json:"status"`
+	Error    string  `json:"error"`
+	Response Collage `json:"response"`
+}
+
+type CommentsResponse struct {
+	Status   string   `json:"status"`
+	Error    string   `json:"error"`
+	Response Comments `json:"response"`
+}
+
 type ConversationResponse struct {
 	Status   string       `json:"status"`
 	Error    string       `json:"error"`
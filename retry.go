@@ -0,0 +1,147 @@
+package whatapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+//RetryPolicy controls how ajax.php requests are retried after transient failures. It is only applied to idempotent GET requests; logins and other actions that change state on the site are never retried.
+type RetryPolicy struct {
+	//MaxAttempts is the total number of attempts, including the first. Values below 2 disable retries.
+	MaxAttempts int
+	//BaseDelay is the backoff before the first retry; it doubles with every further attempt.
+	BaseDelay time.Duration
+	//MaxDelay caps a single backoff. A Retry-After header asking for a longer wait ends the retries instead.
+	MaxDelay time.Duration
+	//RetryStatus lists the HTTP status codes that are considered transient.
+	RetryStatus []int
+	//RetryError reports whether a transport error is transient. If nil, timeouts, connection resets and refused connections are retried.
+	RetryError func(error) bool
+}
+
+//DefaultRetryPolicy is the retry policy used by clients created without WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	RetryStatus: []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+func (p RetryPolicy) retryableStatus(code int) bool {
+	for _, status := range p.RetryStatus {
+		if status == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) retryableError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.RetryError != nil {
+		return p.RetryError(err)
+	}
+	return isTransientError(err)
+}
+
+//backoff returns the jittered exponential delay to wait after the given attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << uint(attempt-1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+func isTransientError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+//retryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//getRetrying sends a GET request for requestURL, retrying transient failures according to the client's retry policy. The caller must close the returned response body.
+func (w *WhatAPI) getRetrying(ctx context.Context, requestURL string) (*http.Response, error) {
+	policy := w.retry
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := w.do(req)
+		if attempt >= policy.MaxAttempts {
+			return resp, err
+		}
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if !policy.retryableError(err) {
+				return nil, err
+			}
+			wait = policy.backoff(attempt)
+		case policy.retryableStatus(resp.StatusCode):
+			wait = policy.backoff(attempt)
+			if d, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if policy.MaxDelay > 0 && d > policy.MaxDelay {
+					return resp, nil
+				}
+				wait = d
+			}
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
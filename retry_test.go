@@ -0,0 +1,50 @@
+package whatapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2020, 1, 2, 10, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 2 * time.Minute, true},
+		{"-5", 0, false},
+		{"soon", 0, false},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{now.Add(-time.Hour).Format(http.TimeFormat), 0, true},
+	} {
+		got, ok := retryAfter(test.header, now)
+		if got != test.want || ok != test.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", test.header, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		max     time.Duration
+	}{
+		{"first attempt", RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, 1, time.Second},
+		{"doubles", RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}, 3, 4 * time.Second},
+		{"capped", RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}, 4, 5 * time.Second},
+		{"overflow", RetryPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}, 80, 5 * time.Second},
+		{"no delay", RetryPolicy{}, 2, 0},
+	} {
+		for i := 0; i < 100; i++ {
+			if d := test.policy.backoff(test.attempt); d < test.max/2 || d > test.max {
+				t.Fatalf("%s: backoff(%d) = %v, want between %v and %v", test.name, test.attempt, d, test.max/2, test.max)
+			}
+		}
+	}
+}
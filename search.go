@@ -0,0 +1,314 @@
+package whatapi
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//SortOrder is the direction search results are sorted in.
+type SortOrder string
+
+const (
+	Ascending  SortOrder = "asc"
+	Descending SortOrder = "desc"
+)
+
+//TorrentOrder is the field torrent search results are sorted by.
+type TorrentOrder string
+
+const (
+	OrderByTime     TorrentOrder = "time"
+	OrderByYear     TorrentOrder = "year"
+	OrderBySize     TorrentOrder = "size"
+	OrderBySnatched TorrentOrder = "snatched"
+	OrderBySeeders  TorrentOrder = "seeders"
+	OrderByLeechers TorrentOrder = "leechers"
+	OrderByRandom   TorrentOrder = "random"
+)
+
+//FreeleechFilter restricts torrent search results by their freeleech status.
+type FreeleechFilter string
+
+const (
+	NormalOnly       FreeleechFilter = "0"
+	FreeleechOnly    FreeleechFilter = "1"
+	NeutralLeechOnly FreeleechFilter = "2"
+	FreeOrNeutral    FreeleechFilter = "3"
+)
+
+//LogFilter restricts torrent search results by their rip log.
+type LogFilter string
+
+const (
+	WithLog      LogFilter = "1"
+	WithoutLog   LogFilter = "0"
+	PerfectLog   LogFilter = "100"
+	ImperfectLog LogFilter = "-1"
+)
+
+//TorrentSearchOptions filters a torrent search. Zero values are not sent.
+type TorrentSearchOptions struct {
+	ArtistName      string
+	GroupName       string
+	RecordLabel     string
+	CatalogueNumber string
+	//YearFrom and YearTo bound the release year; set only one of them for an open range.
+	YearFrom    int
+	YearTo      int
+	FileList    string
+	Format      string
+	Encoding    string
+	Media       string
+	ReleaseType int
+	Tags        []string
+	//AllTags requires every tag to match instead of any of them.
+	AllTags   bool
+	Freeleech FreeleechFilter
+	Log       LogFilter
+	HasCue    *bool
+	Scene     *bool
+	OrderBy   TorrentOrder
+	OrderWay  SortOrder
+	Page      int
+}
+
+//Validate rejects values the site's browse page would silently ignore.
+func (o TorrentSearchOptions) Validate() error {
+	if o.YearFrom < 0 || o.YearTo < 0 || (o.YearTo != 0 && o.YearFrom > o.YearTo) {
+		return &ValidationError{"Year", "invalid range " + strconv.Itoa(o.YearFrom) + "-" + strconv.Itoa(o.YearTo)}
+	}
+	if o.Format != "" && !contains(UploadFormats, o.Format) {
+		return &ValidationError{"Format", "unknown format " + strconv.Quote(o.Format)}
+	}
+	if o.Encoding != "" && !contains(UploadEncodings, o.Encoding) {
+		return &ValidationError{"Encoding", "unknown encoding " + strconv.Quote(o.Encoding)}
+	}
+	if o.Media != "" && !contains(UploadMedia, o.Media) {
+		return &ValidationError{"Media", "unknown media " + strconv.Quote(o.Media)}
+	}
+	if _, ok := ReleaseTypes[o.ReleaseType]; o.ReleaseType != 0 && !ok {
+		return &ValidationError{"ReleaseType", "unknown release type " + strconv.Itoa(o.ReleaseType)}
+	}
+	switch o.Freeleech {
+	case "", NormalOnly, FreeleechOnly, NeutralLeechOnly, FreeOrNeutral:
+	default:
+		return &ValidationError{"Freeleech", "unknown filter " + strconv.Quote(string(o.Freeleech))}
+	}
+	switch o.Log {
+	case "", WithLog, WithoutLog, PerfectLog, ImperfectLog:
+	default:
+		return &ValidationError{"Log", "unknown filter " + strconv.Quote(string(o.Log))}
+	}
+	switch o.OrderBy {
+	case "", OrderByTime, OrderByYear, OrderBySize, OrderBySnatched, OrderBySeeders, OrderByLeechers, OrderByRandom:
+	default:
+		return &ValidationError{"OrderBy", "unknown field " + strconv.Quote(string(o.OrderBy))}
+	}
+	if err := validateSortOrder("OrderWay", o.OrderWay); err != nil {
+		return err
+	}
+	return validatePage(o.Page)
+}
+
+//values returns the options as the site's browse page names them.
+func (o TorrentSearchOptions) values() url.Values {
+	params := url.Values{}
+	setIf := func(key, value string) {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	setIf("artistname", o.ArtistName)
+	setIf("groupname", o.GroupName)
+	setIf("recordlabel", o.RecordLabel)
+	setIf("cataloguenumber", o.CatalogueNumber)
+	if o.YearFrom != 0 || o.YearTo != 0 {
+		year := ""
+		if o.YearFrom != 0 {
+			year = strconv.Itoa(o.YearFrom)
+		}
+		if o.YearTo != o.YearFrom {
+			year += "-"
+			if o.YearTo != 0 {
+				year += strconv.Itoa(o.YearTo)
+			}
+		}
+		params.Set("year", year)
+	}
+	setIf("filelist", o.FileList)
+	setIf("format", o.Format)
+	setIf("encoding", o.Encoding)
+	setIf("media", o.Media)
+	if o.ReleaseType != 0 {
+		params.Set("releasetype", strconv.Itoa(o.ReleaseType))
+	}
+	setTags(params, "taglist", o.Tags, o.AllTags)
+	setIf("freetorrent", string(o.Freeleech))
+	setIf("haslog", string(o.Log))
+	setFlag(params, "hascue", o.HasCue)
+	setFlag(params, "scene", o.Scene)
+	setIf("order_by", string(o.OrderBy))
+	setIf("order_way", string(o.OrderWay))
+	setPage(params, o.Page)
+	return params
+}
+
+//RequestOrder is the field request search results are sorted by.
+type RequestOrder string
+
+const (
+	OrderByRequestYear RequestOrder = "year"
+	OrderByVotes       RequestOrder = "votes"
+	OrderByBounty      RequestOrder = "bounty"
+	OrderByCreated     RequestOrder = "created"
+	OrderByLastVote    RequestOrder = "lastvote"
+	OrderByFilled      RequestOrder = "filled"
+)
+
+//RequestSearchOptions filters a request search. Zero values are not sent.
+type RequestSearchOptions struct {
+	Tags []string
+	//AllTags requires every tag to match instead of any of them.
+	AllTags bool
+	Year    int
+	//ShowFilled includes requests that have already been filled.
+	ShowFilled bool
+	OrderBy    RequestOrder
+	OrderWay   SortOrder
+	Page       int
+}
+
+//Validate rejects values the site's requests page would silently ignore.
+func (o RequestSearchOptions) Validate() error {
+	if o.Year < 0 {
+		return &ValidationError{"Year", "invalid year " + strconv.Itoa(o.Year)}
+	}
+	switch o.OrderBy {
+	case "", OrderByRequestYear, OrderByVotes, OrderByBounty, OrderByCreated, OrderByLastVote, OrderByFilled:
+	default:
+		return &ValidationError{"OrderBy", "unknown field " + strconv.Quote(string(o.OrderBy))}
+	}
+	if err := validateSortOrder("OrderWay", o.OrderWay); err != nil {
+		return err
+	}
+	return validatePage(o.Page)
+}
+
+//values returns the options as the site's requests page names them.
+func (o RequestSearchOptions) values() url.Values {
+	params := url.Values{}
+	setTags(params, "tags", o.Tags, o.AllTags)
+	if o.Year != 0 {
+		params.Set("year", strconv.Itoa(o.Year))
+	}
+	if o.ShowFilled {
+		params.Set("show_filled", "on")
+	}
+	if o.OrderBy != "" {
+		params.Set("order", string(o.OrderBy))
+	}
+	if o.OrderWay != "" {
+		params.Set("sort", string(o.OrderWay))
+	}
+	setPage(params, o.Page)
+	return params
+}
+
+//UserSearchOptions pages through a user search.
+type UserSearchOptions struct {
+	Page int
+}
+
+//Validate rejects an invalid page.
+func (o UserSearchOptions) Validate() error {
+	return validatePage(o.Page)
+}
+
+func (o UserSearchOptions) values() url.Values {
+	params := url.Values{}
+	setPage(params, o.Page)
+	return params
+}
+
+//TopTenOptions selects which top ten list is returned. Zero values use the site's defaults.
+type TopTenOptions struct {
+	//Limit is the number of entries per list: 10, 100 or 250.
+	Limit int
+	//Details picks a single list, e.g. "day" or "seeded" for torrents, "ut" for tags or "ul" for users.
+	Details string
+}
+
+var topTenDetails = map[string][]string{
+	"torrents": {"all", "day", "week", "month", "year", "overall", "snatched", "data", "seeded"},
+	"tags":     {"all", "ut", "ur", "v"},
+	"users":    {"all", "ul", "dl", "numul", "uls", "dls"},
+}
+
+func (o TopTenOptions) validate(kind string) error {
+	switch o.Limit {
+	case 0, 10, 100, 250:
+	default:
+		return &ValidationError{"Limit", "must be 10, 100 or 250"}
+	}
+	if o.Details != "" && !contains(topTenDetails[kind], o.Details) {
+		return &ValidationError{"Details", "unknown " + kind + " list " + strconv.Quote(o.Details)}
+	}
+	return nil
+}
+
+func (o TopTenOptions) values(kind string) url.Values {
+	params := url.Values{}
+	params.Set("type", kind)
+	if o.Limit != 0 {
+		params.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Details != "" {
+		params.Set("details", o.Details)
+	}
+	return params
+}
+
+func validateSortOrder(field string, order SortOrder) error {
+	switch order {
+	case "", Ascending, Descending:
+		return nil
+	}
+	return &ValidationError{field, "must be asc or desc"}
+}
+
+func validatePage(page int) error {
+	if page < 0 {
+		return &ValidationError{"Page", "invalid page " + strconv.Itoa(page)}
+	}
+	return nil
+}
+
+func setTags(params url.Values, key string, tags []string, all bool) {
+	if len(tags) == 0 {
+		return
+	}
+	params.Set(key, strings.Join(tags, ","))
+	if all {
+		params.Set("tags_type", "1")
+	} else {
+		params.Set("tags_type", "0")
+	}
+}
+
+func setFlag(params url.Values, key string, flag *bool) {
+	if flag == nil {
+		return
+	}
+	if *flag {
+		params.Set(key, "1")
+	} else {
+		params.Set(key, "0")
+	}
+}
+
+func setPage(params url.Values, page int) {
+	if page != 0 {
+		params.Set("page", strconv.Itoa(page))
+	}
+}
@@ -0,0 +1,75 @@
+package whatapi
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestTorrentSearchValues(t *testing.T) {
+	yes, no := true, false
+	for _, test := range []struct {
+		name    string
+		options TorrentSearchOptions
+		want    url.Values
+	}{
+		{"empty", TorrentSearchOptions{}, url.Values{}},
+		{"single year", TorrentSearchOptions{YearFrom: 2000, YearTo: 2000}, url.Values{"year": {"2000"}}},
+		{"from year", TorrentSearchOptions{YearFrom: 2000}, url.Values{"year": {"2000-"}}},
+		{"to year", TorrentSearchOptions{YearTo: 2005}, url.Values{"year": {"-2005"}}},
+		{"year range", TorrentSearchOptions{YearFrom: 2000, YearTo: 2005}, url.Values{"year": {"2000-2005"}}},
+		{"any tag", TorrentSearchOptions{Tags: []string{"rock", "jazz"}}, url.Values{"taglist": {"rock,jazz"}, "tags_type": {"0"}}},
+		{"all tags", TorrentSearchOptions{Tags: []string{"rock"}, AllTags: true}, url.Values{"taglist": {"rock"}, "tags_type": {"1"}}},
+		{"flags", TorrentSearchOptions{HasCue: &yes, Scene: &no}, url.Values{"hascue": {"1"}, "scene": {"0"}}},
+		{"filters", TorrentSearchOptions{
+			ArtistName: "Tool", Format: "FLAC", Media: "CD", ReleaseType: 1,
+			Freeleech: FreeleechOnly, Log: PerfectLog, OrderBy: OrderBySeeders, OrderWay: Descending, Page: 2,
+		}, url.Values{
+			"artistname": {"Tool"}, "format": {"FLAC"}, "media": {"CD"}, "releasetype": {"1"},
+			"freetorrent": {"1"}, "haslog": {"100"}, "order_by": {"seeders"}, "order_way": {"desc"}, "page": {"2"},
+		}},
+	} {
+		if got := test.options.values(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: values() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestTorrentSearchValidate(t *testing.T) {
+	for _, test := range []struct {
+		options TorrentSearchOptions
+		field   string
+	}{
+		{TorrentSearchOptions{YearFrom: 2000, YearTo: 2005, Format: "FLAC", Encoding: "Lossless", Media: "Blu-ray"}, ""},
+		{TorrentSearchOptions{YearFrom: 2005, YearTo: 2000}, "Year"},
+		{TorrentSearchOptions{YearTo: -1}, "Year"},
+		{TorrentSearchOptions{Format: "flac"}, "Format"},
+		{TorrentSearchOptions{Encoding: "V0"}, "Encoding"},
+		{TorrentSearchOptions{Media: "Blu-Ray"}, "Media"},
+		{TorrentSearchOptions{ReleaseType: 2}, "ReleaseType"},
+		{TorrentSearchOptions{Freeleech: "4"}, "Freeleech"},
+		{TorrentSearchOptions{OrderWay: "up"}, "OrderWay"},
+		{TorrentSearchOptions{Page: -1}, "Page"},
+	} {
+		err := test.options.Validate()
+		var validationErr *ValidationError
+		switch {
+		case test.field == "" && err != nil:
+			t.Errorf("Validate(%+v) = %v, want nil", test.options, err)
+		case test.field != "" && (!errors.As(err, &validationErr) || validationErr.Field != test.field):
+			t.Errorf("Validate(%+v) = %v, want a ValidationError for %s", test.options, err, test.field)
+		}
+	}
+}
+
+func TestRequestSearchValues(t *testing.T) {
+	options := RequestSearchOptions{Tags: []string{"rock"}, Year: 2001, ShowFilled: true, OrderBy: OrderByBounty, OrderWay: Ascending, Page: 3}
+	want := url.Values{
+		"tags": {"rock"}, "tags_type": {"0"}, "year": {"2001"}, "show_filled": {"on"},
+		"order": {"bounty"}, "sort": {"asc"}, "page": {"3"},
+	}
+	if got := options.values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("values() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,219 @@
+package whatapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+//Session holds everything needed to resume a logged-in session without posting credentials again.
+type Session struct {
+	Cookies []*http.Cookie `json:"cookies"`
+	AuthKey string         `json:"authKey"`
+	PassKey string         `json:"passKey"`
+}
+
+//SessionStore persists a client's session between process runs. Load returns ErrNoSession if nothing has been saved.
+type SessionStore interface {
+	Load(ctx context.Context) (Session, error)
+	Save(ctx context.Context, session Session) error
+	Clear(ctx context.Context) error
+}
+
+//FileSessionStore is a SessionStore keeping the session as JSON in a single file.
+type FileSessionStore struct {
+	Path string
+}
+
+//NewFileSessionStore creates a session store backed by the file at path.
+func NewFileSessionStore(path string) *FileSessionStore {
+	return &FileSessionStore{Path: path}
+}
+
+//Load reads the session from the store's file.
+func (s *FileSessionStore) Load(ctx context.Context) (Session, error) {
+	session := Session{}
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return session, ErrNoSession
+	}
+	if err != nil {
+		return session, err
+	}
+	return session, json.Unmarshal(data, &session)
+}
+
+//Save atomically replaces the store's file with session. The file is only readable by its owner.
+func (s *FileSessionStore) Save(ctx context.Context, session Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+//Clear removes the store's file.
+func (s *FileSessionStore) Clear(ctx context.Context) error {
+	err := os.Remove(s.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+//saveSession writes the current session to the client's session store, if it has one. A failure wraps ErrSessionNotSaved, since the session itself is still valid.
+func (w *WhatAPI) saveSession(ctx context.Context) error {
+	if w.sessions == nil {
+		return nil
+	}
+	u, err := url.Parse(w.baseURL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSessionNotSaved, err)
+	}
+	_, authkey, passkey := w.keys()
+	session := Session{Cookies: w.client.Jar.Cookies(u), AuthKey: authkey, PassKey: passkey}
+	if err := w.sessions.Save(ctx, session); err != nil {
+		return fmt.Errorf("%w: %w", ErrSessionNotSaved, err)
+	}
+	return nil
+}
+
+//RestoreSession loads a session saved by a previous Login from the client's session store and checks that it is still valid with GetAccount. If the session cannot be restored the client stays logged out and Login must be called instead.
+func (w *WhatAPI) RestoreSession(ctx context.Context) error {
+	w.loginMu.Lock()
+	defer w.loginMu.Unlock()
+	if w.sessions == nil {
+		return ErrNoSession
+	}
+	session, err := w.sessions.Load(ctx)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(w.baseURL)
+	if err != nil {
+		return err
+	}
+	w.client.Jar.SetCookies(u, session.Cookies)
+	ctx = context.WithValue(ctx, noReloginKey{}, true)
+	w.beginSession("", session.AuthKey, session.PassKey)
+	account, err := w.GetAccount(ctx)
+	if err != nil {
+		w.endSession()
+		return err
+	}
+	w.setAccount(account)
+	return nil
+}
+
+//CredentialProvider supplies the username and password used to log in again when a session expires.
+type CredentialProvider func(ctx context.Context) (username, password string, err error)
+
+//StaticCredentials returns a CredentialProvider that always supplies the given username and password.
+func StaticCredentials(username, password string) CredentialProvider {
+	return func(ctx context.Context) (string, string, error) {
+		return username, password, nil
+	}
+}
+
+//noReloginKey marks a context whose requests must not trigger another login, either because a login is already in progress or because the request replays one after a login.
+type noReloginKey struct{}
+
+//sessionExpired reports whether resp was redirected away from ajax.php, which the site does to login.php once the session has expired.
+func sessionExpired(resp *http.Response) bool {
+	return path.Base(resp.Request.URL.Path) != "ajax.php"
+}
+
+//isJSON reports whether body looks like a JSON object or array. Some ajax.php actions answer with an HTML page instead, which is not a sign of an expired session.
+func isJSON(body []byte) bool {
+	body = bytes.TrimSpace(body)
+	return len(body) > 0 && (body[0] == '{' || body[0] == '[')
+}
+
+//relogin handles an expired session by logging in again with the client's credential provider and replaying the request for requestURL once. generation identifies the session the request was sent with, so that concurrent requests noticing the same expiry only log in once.
+func (w *WhatAPI) relogin(ctx context.Context, generation uint64, requestURL string, responseObj interface{}) error {
+	if w.credentials == nil || ctx.Value(noReloginKey{}) != nil {
+		w.expire(generation)
+		return ErrSessionExpired
+	}
+	if err := w.renewSession(ctx, generation); err != nil {
+		return fmt.Errorf("%w: %w", ErrSessionExpired, err)
+	}
+	return w.GetJSON(context.WithValue(ctx, noReloginKey{}, true), requestURL, responseObj)
+}
+
+//loginRedirect handles a page request sent with the given session generation that was redirected to login.php. Clients logged in with LoginWithToken have no cookie session for the site's pages to use, which is reported as such instead of expiring the token session.
+func (w *WhatAPI) loginRedirect(generation uint64) error {
+	w.mu.RLock()
+	tokenSession := w.apiToken != ""
+	w.mu.RUnlock()
+	if tokenSession {
+		return ErrCookieSessionRequired
+	}
+	w.expire(generation)
+	return ErrSessionExpired
+}
+
+//expire marks the client logged out unless it has logged in again since the given session generation.
+func (w *WhatAPI) expire(generation uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.generation == generation {
+		w.loggedIn = false
+	}
+}
+
+//renewSession logs in again unless another goroutine already did so since the given session generation. Requests sent meanwhile still use the expired session and wait here in turn. If the renewal fails, the waiting requests get the same error instead of posting the credentials again, which could get the account locked out or the IP banned.
+func (w *WhatAPI) renewSession(ctx context.Context, generation uint64) error {
+	w.loginMu.Lock()
+	defer w.loginMu.Unlock()
+	w.mu.RLock()
+	current := w.generation == generation
+	failed := w.renewFailed == generation && w.renewErr != nil
+	renewErr := w.renewErr
+	w.mu.RUnlock()
+	if !current {
+		return nil
+	}
+	if failed {
+		return renewErr
+	}
+	username, password, err := w.credentials(ctx)
+	if err == nil {
+		err = w.login(ctx, username, password, w.totp)
+	}
+	if err != nil {
+		w.failRenewal(generation, err)
+		return err
+	}
+	//The renewed session works whether or not it can be stored; the store keeps the previous session until the next save succeeds.
+	w.saveSession(ctx)
+	return nil
+}
+
+//failRenewal marks the client logged out after the given session generation could not be renewed, and records err for the requests still waiting to renew it.
+func (w *WhatAPI) failRenewal(generation uint64, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.generation == generation {
+		w.loggedIn = false
+		w.renewErr, w.renewFailed = err, generation
+	}
+}
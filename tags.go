@@ -0,0 +1,91 @@
+package whatapi
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	tagInvalidChars = regexp.MustCompile(`[^a-z0-9.]`)
+	tagRepeatedDots = regexp.MustCompile(`\.{2,}`)
+	groupPageTag    = regexp.MustCompile(`(?s)<li[^>]*>(.*?)</li>`)
+	tagListLink     = regexp.MustCompile(`taglist=([^"&]+)`)
+	tagIDLink       = regexp.MustCompile(`tagid=(\d+)`)
+)
+
+//NormalizeTag turns a tag name into the form the site stores: lowercase, spaces replaced with dots and any character other than letters, digits and dots removed.
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	tag = strings.Join(strings.Fields(tag), ".")
+	tag = tagInvalidChars.ReplaceAllString(tag, "")
+	tag = tagRepeatedDots.ReplaceAllString(tag, ".")
+	return strings.Trim(tag, ".")
+}
+
+//normalizeTag normalizes tag and resolves it through the client's tag aliases.
+func (w *WhatAPI) normalizeTag(tag string) string {
+	tag = NormalizeTag(tag)
+	if alias, ok := w.tagAliases[tag]; ok {
+		return alias
+	}
+	return tag
+}
+
+//AddTag adds a tag to the torrent group with the provided id.
+func (w *WhatAPI) AddTag(ctx context.Context, groupID int, tag string) error {
+	tag = w.normalizeTag(tag)
+	if tag == "" {
+		return &ValidationError{"tag", "empty after normalization"}
+	}
+	params := url.Values{}
+	params.Set("action", "add_tag")
+	params.Set("groupid", strconv.Itoa(groupID))
+	params.Set("tagname", tag)
+	_, err := w.postAction(ctx, "torrents.php", params)
+	return err
+}
+
+//VoteTag votes a tag of the torrent group with the provided id up or down.
+func (w *WhatAPI) VoteTag(ctx context.Context, groupID int, tag string, up bool) error {
+	way := "down"
+	if up {
+		way = "up"
+	}
+	return w.groupTagAction(ctx, groupID, tag, url.Values{"action": {"vote_tag"}, "way": {way}})
+}
+
+//RemoveTag removes a tag from the torrent group with the provided id.
+func (w *WhatAPI) RemoveTag(ctx context.Context, groupID int, tag string) error {
+	return w.groupTagAction(ctx, groupID, tag, url.Values{"action": {"delete_tag"}})
+}
+
+//groupTagAction performs an action on one tag of a torrent group. The site identifies tags by id, which the JSON API does not expose, so the id is looked up on the group's page.
+func (w *WhatAPI) groupTagAction(ctx context.Context, groupID int, tag string, params url.Values) error {
+	tag = w.normalizeTag(tag)
+	page, err := w.getAction(ctx, "torrents.php", url.Values{"id": {strconv.Itoa(groupID)}})
+	if err != nil {
+		return err
+	}
+	tagID := ""
+	for _, item := range groupPageTag.FindAllSubmatch(page, -1) {
+		name := tagListLink.FindSubmatch(item[1])
+		id := tagIDLink.FindSubmatch(item[1])
+		if name == nil || id == nil {
+			continue
+		}
+		if unescaped, err := url.QueryUnescape(string(name[1])); err == nil && unescaped == tag {
+			tagID = string(id[1])
+			break
+		}
+	}
+	if tagID == "" {
+		return &ValidationError{"tag", strconv.Quote(tag) + " is not a tag of group " + strconv.Itoa(groupID)}
+	}
+	params.Set("groupid", strconv.Itoa(groupID))
+	params.Set("tagid", tagID)
+	_, err = w.getAction(ctx, "torrents.php", params)
+	return err
+}
@@ -0,0 +1,24 @@
+package whatapi
+
+import "testing"
+
+func TestNormalizeTag(t *testing.T) {
+	for _, test := range []struct {
+		tag, want string
+	}{
+		{"rock", "rock"},
+		{"Hip Hop", "hip.hop"},
+		{"  post   rock  ", "post.rock"},
+		{"hip-hop", "hiphop"},
+		{"drum & bass", "drum.bass"},
+		{"1990s", "1990s"},
+		{"..electronic..", "electronic"},
+		{"rock...and roll", "rock.and.roll"},
+		{"!!!", ""},
+		{"", ""},
+	} {
+		if got := NormalizeTag(test.tag); got != test.want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", test.tag, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package whatapi
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//ArtistRole is the importance of an artist on a release, matching the roles in GroupType.MusicInfo.
+type ArtistRole int
+
+const (
+	RoleMain      ArtistRole = 1
+	RoleGuest     ArtistRole = 2
+	RoleRemixer   ArtistRole = 3
+	RoleComposer  ArtistRole = 4
+	RoleConductor ArtistRole = 5
+	RoleDJ        ArtistRole = 6
+	RoleProducer  ArtistRole = 7
+)
+
+//UploadArtist is an artist credited on an upload.
+type UploadArtist struct {
+	Name string
+	Role ArtistRole
+}
+
+//UploadFile is a file attached to an upload.
+type UploadFile struct {
+	Name string
+	Data []byte
+}
+
+//UploadForm describes a torrent upload. Fields left empty are not sent.
+type UploadForm struct {
+	//Category is the index of the upload category on the site; 0 is Music.
+	Category int
+	//GroupID adds the torrent to an existing torrent group instead of creating one.
+	GroupID         int
+	Title           string
+	Artists         []UploadArtist
+	Year            int
+	RecordLabel     string
+	CatalogueNumber string
+	ReleaseType     int
+
+	Remastered              bool
+	RemasterYear            int
+	RemasterTitle           string
+	RemasterRecordLabel     string
+	RemasterCatalogueNumber string
+	Scene                   bool
+
+	Media    string
+	Format   string
+	Encoding string
+
+	Tags               []string
+	Image              string
+	AlbumDescription   string
+	ReleaseDescription string
+	LogFiles           []UploadFile
+}
+
+//UploadResult identifies the torrent created by Upload.
+type UploadResult struct {
+	GroupID   int
+	TorrentID int
+}
+
+//ReleaseTypes maps the release types accepted by the upload form to their names.
+var ReleaseTypes = map[int]string{
+	1: "Album", 3: "Soundtrack", 5: "EP", 6: "Anthology", 7: "Compilation", 9: "Single",
+	11: "Live album", 13: "Remix", 14: "Bootleg", 15: "Interview", 16: "Mixtape",
+	17: "Demo", 18: "Concert Recording", 19: "DJ Mix", 21: "Unknown",
+}
+
+var (
+	//UploadMedia lists the media accepted by the upload form.
+	UploadMedia = []string{"CD", "DVD", "Vinyl", "Soundboard", "SACD", "DAT", "Cassette", "WEB", "Blu-ray"}
+	//UploadFormats lists the formats accepted by the upload form.
+	UploadFormats = []string{"MP3", "FLAC", "Ogg Vorbis", "AAC", "AC3", "DTS"}
+	//UploadEncodings lists the encodings (bitrates) accepted by the upload form.
+	UploadEncodings = []string{"192", "APS (VBR)", "V2 (VBR)", "V1 (VBR)", "256", "APX (VBR)", "V0 (VBR)", "q8.x (VBR)", "320", "Lossless", "24bit Lossless", "Other"}
+)
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+//Validate checks the form the way the site's upload page does, so that mistakes are caught before the upload is sent.
+func (f UploadForm) Validate() error {
+	newGroup := f.GroupID == 0
+	if newGroup && strings.TrimSpace(f.Title) == "" {
+		return &ValidationError{"Title", "required"}
+	}
+	if f.Category != 0 {
+		if newGroup && len(f.Tags) == 0 {
+			return &ValidationError{"Tags", "at least one tag is required"}
+		}
+		return nil
+	}
+	if newGroup {
+		hasMain := false
+		for _, artist := range f.Artists {
+			if strings.TrimSpace(artist.Name) == "" {
+				return &ValidationError{"Artists", "artist name is empty"}
+			}
+			if artist.Role < RoleMain || artist.Role > RoleProducer {
+				return &ValidationError{"Artists", "unknown role for " + artist.Name}
+			}
+			hasMain = hasMain || artist.Role == RoleMain || artist.Role == RoleComposer || artist.Role == RoleConductor || artist.Role == RoleDJ
+		}
+		if !hasMain {
+			return &ValidationError{"Artists", "a main artist, composer, conductor or DJ is required"}
+		}
+		if f.Year < 1000 {
+			return &ValidationError{"Year", "required"}
+		}
+		if _, ok := ReleaseTypes[f.ReleaseType]; !ok {
+			return &ValidationError{"ReleaseType", "unknown release type " + strconv.Itoa(f.ReleaseType)}
+		}
+		if len(f.Tags) == 0 {
+			return &ValidationError{"Tags", "at least one tag is required"}
+		}
+		if strings.TrimSpace(f.AlbumDescription) == "" {
+			return &ValidationError{"AlbumDescription", "required"}
+		}
+	}
+	if f.Remastered && f.RemasterYear < 1000 && f.ReleaseType != 21 {
+		return &ValidationError{"RemasterYear", "required for remasters"}
+	}
+	if !contains(UploadMedia, f.Media) {
+		return &ValidationError{"Media", "unknown media " + strconv.Quote(f.Media)}
+	}
+	if !contains(UploadFormats, f.Format) {
+		return &ValidationError{"Format", "unknown format " + strconv.Quote(f.Format)}
+	}
+	if !contains(UploadEncodings, f.Encoding) {
+		return &ValidationError{"Encoding", "unknown encoding " + strconv.Quote(f.Encoding)}
+	}
+	lossless := f.Encoding == "Lossless" || f.Encoding == "24bit Lossless"
+	if (f.Format == "FLAC") != lossless {
+		return &ValidationError{"Encoding", f.Encoding + " is not valid for " + f.Format}
+	}
+	if len(f.LogFiles) > 0 && (f.Media != "CD" || f.Format != "FLAC") {
+		return &ValidationError{"LogFiles", "logs can only be added to CD FLAC uploads"}
+	}
+	return nil
+}
+
+//values returns the form fields as the site's upload page names them.
+func (f UploadForm) values() url.Values {
+	params := url.Values{}
+	params.Set("submit", "true")
+	params.Set("type", strconv.Itoa(f.Category))
+	setIf := func(key, value string) {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	setInt := func(key string, value int) {
+		if value != 0 {
+			params.Set(key, strconv.Itoa(value))
+		}
+	}
+	setBool := func(key string, value bool) {
+		if value {
+			params.Set(key, "on")
+		}
+	}
+	setInt("groupid", f.GroupID)
+	setIf("title", f.Title)
+	for _, artist := range f.Artists {
+		params.Add("artists[]", artist.Name)
+		params.Add("importance[]", strconv.Itoa(int(artist.Role)))
+	}
+	setInt("year", f.Year)
+	setIf("record_label", f.RecordLabel)
+	setIf("catalogue_number", f.CatalogueNumber)
+	setInt("releasetype", f.ReleaseType)
+	setBool("remaster", f.Remastered)
+	setInt("remaster_year", f.RemasterYear)
+	setIf("remaster_title", f.RemasterTitle)
+	setIf("remaster_record_label", f.RemasterRecordLabel)
+	setIf("remaster_catalogue_number", f.RemasterCatalogueNumber)
+	setBool("scene", f.Scene)
+	setIf("media", f.Media)
+	setIf("format", f.Format)
+	setIf("bitrate", f.Encoding)
+	setIf("tags", strings.Join(f.Tags, ", "))
+	setIf("image", f.Image)
+	setIf("album_desc", f.AlbumDescription)
+	setIf("release_desc", f.ReleaseDescription)
+	return params
+}
+
+var uploadFormError = regexp.MustCompile(`(?s)<p style="color: red;[^"]*">(.*?)</p>`)
+
+//Upload validates form and uploads the provided .torrent file with it.
+func (w *WhatAPI) Upload(ctx context.Context, torrent UploadFile, form UploadForm) (UploadResult, error) {
+	result := UploadResult{}
+	if err := form.Validate(); err != nil {
+		return result, err
+	}
+	if _, err := ParseMetaInfo(torrent.Data); err != nil {
+		return result, &ValidationError{"torrent", "not a .torrent file: " + err.Error()}
+	}
+	w.mu.RLock()
+	loggedIn, authkey, generation := w.loggedIn, w.authkey, w.generation
+	w.mu.RUnlock()
+	if !loggedIn {
+		return result, ErrNotLoggedIn
+	}
+	params := form.values()
+	params.Set("auth", authkey)
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for key, values := range params {
+		for _, value := range values {
+			if err := mw.WriteField(key, value); err != nil {
+				return result, err
+			}
+		}
+	}
+	writeFile := func(field string, file UploadFile) error {
+		part, err := mw.CreateFormFile(field, file.Name)
+		if err != nil {
+			return err
+		}
+		_, err = part.Write(file.Data)
+		return err
+	}
+	if err := writeFile("file_input", torrent); err != nil {
+		return result, err
+	}
+	for _, log := range form.LogFiles {
+		if err := writeFile("logfiles[]", log); err != nil {
+			return result, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return result, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.baseURL+"upload.php", &buf)
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, body, err := w.send(req)
+	if err != nil {
+		return result, err
+	}
+	requestURL := w.baseURL + "upload.php?" + params.Encode()
+	if resp.StatusCode != 200 {
+		return result, newAPIError(requestURL, resp.StatusCode, pageError(body))
+	}
+	landed := resp.Request.URL
+	if path.Base(landed.Path) == "login.php" {
+		return result, w.loginRedirect(generation)
+	}
+	if path.Base(landed.Path) != "torrents.php" {
+		message := pageError(body)
+		if match := uploadFormError.FindSubmatch(body); match != nil {
+			message = pageText(match[1])
+		}
+		return result, newAPIError(requestURL, resp.StatusCode, message)
+	}
+	result.GroupID, _ = strconv.Atoi(landed.Query().Get("id"))
+	result.TorrentID, _ = strconv.Atoi(landed.Query().Get("torrentid"))
+	return result, nil
+}
@@ -1,17 +1,10 @@
 package whatapi
 
 import (
-	"errors"
-	"fmt"
+	"html"
 	"net/url"
-)
-
-var (
-	errLoginFailed         = errors.New("Login failed")
-	errRequestFailed       = errors.New("Request failed")
-	errRequestFailedLogin  = errors.New("Request failed: not logged in")
-	errRequestFailedReason = func(err string) error { return fmt.Errorf("Request failed: %s", err) }
-	debugMode              = false
+	"regexp"
+	"strings"
 )
 
 func buildURL(baseURL, path, action string, params url.Values) (string, error) {
@@ -30,18 +23,35 @@ func buildURL(baseURL, path, action string, params url.Values) (string, error) {
 		}
 	}
 	u.RawQuery = query.Encode()
-	if debugMode {
-		fmt.Println(u.String())
-	}
 	return u.String(), nil
 }
 
-func checkResponseStatus(status, errorStr string) error {
+func checkResponseStatus(requestURL, status, errorStr string) error {
 	if status != "success" {
-		if errorStr != "" {
-			return errRequestFailedReason(errorStr)
-		}
-		return errRequestFailed
+		return newAPIError(requestURL, 200, errorStr)
 	}
 	return nil
 }
+
+var (
+	errorPageTitle   = regexp.MustCompile(`<title>\s*Error\b`)
+	errorPageMessage = regexp.MustCompile(`(?s)<div class="box pad">\s*<p>(.*?)</p>`)
+	htmlTag          = regexp.MustCompile(`<[^>]*>`)
+)
+
+//pageError extracts the message from the error page Gazelle renders when a form action fails, or returns "" if body is not an error page.
+func pageError(body []byte) string {
+	if !errorPageTitle.Match(body) {
+		return ""
+	}
+	match := errorPageMessage.FindSubmatch(body)
+	if match == nil {
+		return "Error"
+	}
+	return pageText(match[1])
+}
+
+//pageText strips the markup from an HTML fragment.
+func pageText(fragment []byte) string {
+	return strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(string(fragment), "")))
+}
@@ -2,16 +2,22 @@
 package whatapi
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
+	"path"
 	"strconv"
+	"strings"
+	"sync"
 )
 
-//NewWhatAPI creates a new client for the What.CD API using the provided URL.
-func NewWhatAPI(url string) (*WhatAPI, error) {
+//NewWhatAPI creates a new client for the What.CD API using the provided URL and options.
+func NewWhatAPI(url string, options ...Option) (*WhatAPI, error) {
 	w := new(WhatAPI)
 	w.baseURL = url
 	cookieJar, err := cookiejar.New(nil)
@@ -19,124 +25,362 @@ func NewWhatAPI(url string) (*WhatAPI, error) {
 		return w, err
 	}
 	w.client = &http.Client{Jar: cookieJar}
-	return w, err
+	w.limiter = defaultRateLimiter()
+	w.retry = DefaultRetryPolicy
+	for _, option := range options {
+		if err := option(w); err != nil {
+			return w, err
+		}
+	}
+	for _, option := range w.clientOptions {
+		if err := option(w.client); err != nil {
+			return w, err
+		}
+	}
+	w.clientOptions = nil
+	return w, nil
 }
 
-//WhatAPI represents a client for the What.CD API.
+//WhatAPI represents a client for the What.CD API. It is safe for concurrent use by multiple goroutines.
 type WhatAPI struct {
-	baseURL  string
-	client   *http.Client
-	authkey  string
-	passkey  string
-	loggedIn bool
+	baseURL     string
+	client      *http.Client
+	limiter     *RateLimiter
+	retry       RetryPolicy
+	userAgent   string
+	sessions    SessionStore
+	credentials CredentialProvider
+	totp        TOTPProvider
+	debug       io.Writer
+	tagAliases  map[string]string
+
+	//clientOptions are applied to client after every option has run, so that they survive WithHTTPClient.
+	clientOptions []func(*http.Client) error
+
+	//loginMu serializes logins, logouts and session renewals.
+	loginMu sync.Mutex
+
+	//mu guards the login state below.
+	mu         sync.RWMutex
+	loggedIn   bool
+	userID     int
+	authkey    string
+	passkey    string
+	apiToken   string
+	generation uint64
+	//renewErr is the error returned by the failed attempt to renew session generation renewFailed.
+	renewErr    error
+	renewFailed uint64
+}
+
+//keys returns whether the client is logged in along with its authkey and passkey.
+func (w *WhatAPI) keys() (loggedIn bool, authkey, passkey string) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.loggedIn, w.authkey, w.passkey
+}
+
+//beginSession marks the client logged in, starting a new session generation.
+func (w *WhatAPI) beginSession(apiToken, authkey, passkey string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.loggedIn, w.apiToken, w.authkey, w.passkey = true, apiToken, authkey, passkey
+	w.generation++
+}
+
+//setAccount stores the user id, authkey and passkey of the current session.
+func (w *WhatAPI) setAccount(account Account) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.userID, w.authkey, w.passkey = account.ID, account.AuthKey, account.PassKey
+}
+
+//endSession marks the client logged out and forgets its keys.
+func (w *WhatAPI) endSession() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.loggedIn, w.apiToken, w.userID, w.authkey, w.passkey = false, "", 0, "", ""
+}
+
+//do sends req once the rate limiter allows it.
+func (w *WhatAPI) do(req *http.Request) (*http.Response, error) {
+	if w.userAgent != "" {
+		req.Header.Set("User-Agent", w.userAgent)
+	}
+	w.mu.RLock()
+	apiToken := w.apiToken
+	w.mu.RUnlock()
+	if apiToken != "" {
+		req.Header.Set("Authorization", apiToken)
+	}
+	if w.limiter != nil {
+		if err := w.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	if w.debug != nil {
+		fmt.Fprintln(w.debug, req.Method, req.URL)
+	}
+	return w.client.Do(req)
+}
+
+//postForm sends a form-encoded POST request to the page at pagePath and returns the response along with its body. POST requests change state on the site and are never retried.
+func (w *WhatAPI) postForm(ctx context.Context, pagePath string, params url.Values) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.baseURL+pagePath, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return w.send(req)
 }
 
-//GetJSON sends a HTTP GET request to the API and decodes the JSON response into responseObj.
-func (w *WhatAPI) GetJSON(requestURL string, responseObj interface{}) error {
-	if w.loggedIn {
-		resp, err := w.client.Get(requestURL)
+//send sends req once and returns the response along with its body.
+func (w *WhatAPI) send(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := w.do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, body, nil
+}
+
+//postAction sends an authenticated form to the page at pagePath, the way the site's own forms perform actions that change state, and returns the body of the resulting page. The authkey is added to params.
+func (w *WhatAPI) postAction(ctx context.Context, pagePath string, params url.Values) ([]byte, error) {
+	return w.action(ctx, http.MethodPost, pagePath, params)
+}
+
+//getAction is like postAction for the site's actions that are triggered by links rather than forms. Like postAction it is never retried.
+func (w *WhatAPI) getAction(ctx context.Context, pagePath string, params url.Values) ([]byte, error) {
+	return w.action(ctx, http.MethodGet, pagePath, params)
+}
+
+func (w *WhatAPI) action(ctx context.Context, method, pagePath string, params url.Values) ([]byte, error) {
+	w.mu.RLock()
+	loggedIn, authkey, generation := w.loggedIn, w.authkey, w.generation
+	w.mu.RUnlock()
+	if !loggedIn {
+		return nil, ErrNotLoggedIn
+	}
+	params.Set("auth", authkey)
+	requestURL := w.baseURL + pagePath + "?" + params.Encode()
+	var req *http.Request
+	var err error
+	if method == http.MethodPost {
+		req, err = http.NewRequestWithContext(ctx, method, w.baseURL+pagePath, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, requestURL, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp, body, err := w.send(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return body, newAPIError(requestURL, resp.StatusCode, pageError(body))
+	}
+	if path.Base(resp.Request.URL.Path) == "login.php" {
+		return body, w.loginRedirect(generation)
+	}
+	if message := pageError(body); message != "" {
+		return body, newAPIError(requestURL, resp.StatusCode, message)
+	}
+	return body, nil
+}
+
+//GetJSON sends a HTTP GET request to the API and decodes the JSON response into responseObj. Transient failures are retried according to the client's retry policy. An expired session, which the site signals by redirecting to login.php, is renewed if the client has credentials. Any other answer that is not JSON is returned as an APIError rather than treated as an expiry, since some ajax.php actions answer with HTML pages.
+func (w *WhatAPI) GetJSON(ctx context.Context, requestURL string, responseObj interface{}) error {
+	w.mu.RLock()
+	loggedIn, generation := w.loggedIn, w.generation
+	w.mu.RUnlock()
+	if loggedIn {
+		resp, err := w.getRetrying(ctx, requestURL)
 		if err != nil {
 			return err
 		}
 		defer resp.Body.Close()
 		if resp.StatusCode != 200 {
-			return errRequestFailedReason("Status Code " + resp.Status)
+			return newAPIError(requestURL, resp.StatusCode, "")
 		}
 		body, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
 			return err
 		}
+		if sessionExpired(resp) {
+			return w.relogin(ctx, generation, requestURL, responseObj)
+		}
+		if !isJSON(body) {
+			return newAPIError(requestURL, resp.StatusCode, "response is not JSON")
+		}
 		return json.Unmarshal(body, responseObj)
 
 	}
-	return errRequestFailedLogin
+	return ErrNotLoggedIn
 }
 
 //CreateDownloadURL constructs a download URL using the provided torrent id.
 func (w *WhatAPI) CreateDownloadURL(id int) (string, error) {
-	if w.loggedIn {
+	if loggedIn, authkey, passkey := w.keys(); loggedIn {
 		params := url.Values{}
 		params.Set("action", "download")
 		params.Set("id", strconv.Itoa(id))
-		params.Set("authkey", w.authkey)
-		params.Set("torrent_pass", w.passkey)
+		params.Set("authkey", authkey)
+		params.Set("torrent_pass", passkey)
 		downloadURL, err := buildURL(w.baseURL, "torrents.php", "", params)
 		if err != nil {
 			return "", err
 		}
 		return downloadURL, nil
 	}
-	return "", errRequestFailedLogin
+	return "", ErrNotLoggedIn
 
 }
 
-//Login logs in to the API using the provided credentials.
-func (w *WhatAPI) Login(username, password string) error {
+//Login logs in to the API using the provided credentials. If the account has two-factor authentication enabled, the code is requested from the provider set with WithTOTP. If the session cannot be written to the session store, the client is still logged in and the error wraps ErrSessionNotSaved.
+func (w *WhatAPI) Login(ctx context.Context, username, password string) error {
+	w.loginMu.Lock()
+	defer w.loginMu.Unlock()
+	if err := w.login(ctx, username, password, w.totp); err != nil {
+		return err
+	}
+	return w.saveSession(ctx)
+}
+
+//LoginWithTOTP logs in to an account with two-factor authentication enabled using the provided credentials and TOTP code. Like Login, it returns an error wrapping ErrSessionNotSaved if only saving the session failed.
+func (w *WhatAPI) LoginWithTOTP(ctx context.Context, username, password, code string) error {
+	w.loginMu.Lock()
+	defer w.loginMu.Unlock()
+	if err := w.login(ctx, username, password, func(context.Context) (string, error) { return code, nil }); err != nil {
+		return err
+	}
+	return w.saveSession(ctx)
+}
+
+func (w *WhatAPI) login(ctx context.Context, username, password string, totp TOTPProvider) error {
+	ctx = context.WithValue(ctx, noReloginKey{}, true)
 	params := url.Values{}
 	params.Set("username", username)
 	params.Set("password", password)
-	resp, err := w.client.PostForm(w.baseURL+"login.php?", params)
+	resp, body, err := w.postForm(ctx, "login.php", params)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	if resp.Request.URL.String()[len(w.baseURL):] != "index.php" {
-		return errLoginFailed
+	if twoFactorChallenge(resp, body) {
+		if totp == nil {
+			return Err2FARequired
+		}
+		code, err := totp(ctx)
+		if err != nil {
+			return err
+		}
+		resp, body, err = w.postForm(ctx, "login.php?act=2fa", url.Values{"2fa": {code}})
+		if err != nil {
+			return err
+		}
+		if twoFactorChallenge(resp, body) {
+			return ErrBad2FACode
+		}
+	}
+	if path.Base(resp.Request.URL.Path) != "index.php" {
+		return loginFailure(body)
 	}
-	w.loggedIn = true
-	account, err := w.GetAccount()
+	//The previous keys stay in place until GetAccount replaces them, so that requests sent during a renewal still carry them.
+	_, authkey, passkey := w.keys()
+	w.beginSession("", authkey, passkey)
+	account, err := w.GetAccount(ctx)
 	if err != nil {
+		w.endSession()
 		return err
 	}
-	w.authkey, w.passkey = account.AuthKey, account.PassKey
+	w.setAccount(account)
 	return nil
 }
 
-//Logout logs out of the API, ending the current session.
-func (w *WhatAPI) Logout() error {
-	params := url.Values{"auth": {w.authkey}}
+//LoginWithToken authenticates with an API token sent in the Authorization header instead of a session cookie, as supported by newer Gazelle forks. The token is checked by fetching the authkey and passkey with GetAccount. Page actions such as SendMessage return ErrCookieSessionRequired if the site only accepts the token on ajax.php.
+func (w *WhatAPI) LoginWithToken(ctx context.Context, token string) error {
+	w.loginMu.Lock()
+	defer w.loginMu.Unlock()
+	ctx = context.WithValue(ctx, noReloginKey{}, true)
+	w.beginSession(token, "", "")
+	account, err := w.GetAccount(ctx)
+	if err != nil {
+		w.endSession()
+		return err
+	}
+	w.setAccount(account)
+	return nil
+}
+
+//Logout logs out of the API, ending the current session. A client authenticated with LoginWithToken just forgets its token.
+func (w *WhatAPI) Logout(ctx context.Context) error {
+	w.loginMu.Lock()
+	defer w.loginMu.Unlock()
+	w.mu.RLock()
+	apiToken, authkey := w.apiToken, w.authkey
+	w.mu.RUnlock()
+	if apiToken != "" {
+		w.endSession()
+		return nil
+	}
+	params := url.Values{"auth": {authkey}}
 	requestURL, err := buildURL(w.baseURL, "logout.php", "", params)
 	if err != nil {
 		return err
 	}
-	_, err = w.client.Get(requestURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
 	if err != nil {
 		return err
 	}
-	w.loggedIn, w.authkey, w.passkey = false, "", ""
+	resp, err := w.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	w.endSession()
+	if w.sessions != nil {
+		return w.sessions.Clear(ctx)
+	}
 	return nil
 }
 
 //GetAccount retrieves account information for the current user.
-func (w *WhatAPI) GetAccount() (Account, error) {
+func (w *WhatAPI) GetAccount(ctx context.Context) (Account, error) {
 	account := AccountResponse{}
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "index", url.Values{})
 	if err != nil {
 		return account.Response, err
 	}
-	err = w.GetJSON(requestURL, &account)
+	err = w.GetJSON(ctx, requestURL, &account)
 	if err != nil {
 		return account.Response, err
 	}
-	return account.Response, checkResponseStatus(account.Status, account.Error)
+	return account.Response, checkResponseStatus(requestURL, account.Status, account.Error)
 }
 
 //GetMailbox retrieves mailbox information for the current user using the provided parameters.
-func (w *WhatAPI) GetMailbox(params url.Values) (Mailbox, error) {
+func (w *WhatAPI) GetMailbox(ctx context.Context, params url.Values) (Mailbox, error) {
 	mailbox := MailboxResponse{}
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "inbox", params)
 	if err != nil {
 		return mailbox.Response, err
 	}
-	err = w.GetJSON(requestURL, &mailbox)
+	err = w.GetJSON(ctx, requestURL, &mailbox)
 	if err != nil {
 		return mailbox.Response, err
 	}
-	return mailbox.Response, checkResponseStatus(mailbox.Status, mailbox.Error)
+	return mailbox.Response, checkResponseStatus(requestURL, mailbox.Status, mailbox.Error)
 }
 
 //GetConversation retrieves conversation information for the current user using the provided conversation id and parameters.
-func (w *WhatAPI) GetConversation(id int) (Conversation, error) {
+func (w *WhatAPI) GetConversation(ctx context.Context, id int) (Conversation, error) {
 	conversation := ConversationResponse{}
 	params := url.Values{}
 	params.Set("type", "viewconv")
@@ -145,58 +389,58 @@ func (w *WhatAPI) GetConversation(id int) (Conversation, error) {
 	if err != nil {
 		return conversation.Response, err
 	}
-	err = w.GetJSON(requestURL, &conversation)
+	err = w.GetJSON(ctx, requestURL, &conversation)
 	if err != nil {
 		return conversation.Response, err
 	}
-	return conversation.Response, checkResponseStatus(conversation.Status, conversation.Error)
+	return conversation.Response, checkResponseStatus(requestURL, conversation.Status, conversation.Error)
 }
 
 //GetNotifications retrieves notification information using the specifed parameters.
-func (w *WhatAPI) GetNotifications(params url.Values) (Notifications, error) {
+func (w *WhatAPI) GetNotifications(ctx context.Context, params url.Values) (Notifications, error) {
 	notifications := NotificationsResponse{}
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "notifications", params)
 	if err != nil {
 		return notifications.Response, err
 	}
-	err = w.GetJSON(requestURL, &notifications)
+	err = w.GetJSON(ctx, requestURL, &notifications)
 	if err != nil {
 		return notifications.Response, err
 	}
-	return notifications.Response, checkResponseStatus(notifications.Status, notifications.Error)
+	return notifications.Response, checkResponseStatus(requestURL, notifications.Status, notifications.Error)
 }
 
 //GetAnnouncements retrieves announcement information.
-func (w *WhatAPI) GetAnnouncements() (Announcements, error) {
+func (w *WhatAPI) GetAnnouncements(ctx context.Context) (Announcements, error) {
 	params := url.Values{}
 	announcements := AnnouncementsResponse{}
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "announcements", params)
 	if err != nil {
 		return announcements.Response, err
 	}
-	err = w.GetJSON(requestURL, &announcements)
+	err = w.GetJSON(ctx, requestURL, &announcements)
 	if err != nil {
 		return announcements.Response, err
 	}
-	return announcements.Response, checkResponseStatus(announcements.Status, announcements.Error)
+	return announcements.Response, checkResponseStatus(requestURL, announcements.Status, announcements.Error)
 }
 
 //GetSubscriptions retrieves forum subscription information for the current user using the provided parameters.
-func (w *WhatAPI) GetSubscriptions(params url.Values) (Subscriptions, error) {
+func (w *WhatAPI) GetSubscriptions(ctx context.Context, params url.Values) (Subscriptions, error) {
 	subscriptions := SubscriptionsResponse{}
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "subscriptions", params)
 	if err != nil {
 		return subscriptions.Response, err
 	}
-	err = w.GetJSON(requestURL, &subscriptions)
+	err = w.GetJSON(ctx, requestURL, &subscriptions)
 	if err != nil {
 		return subscriptions.Response, err
 	}
-	return subscriptions.Response, checkResponseStatus(subscriptions.Status, subscriptions.Error)
+	return subscriptions.Response, checkResponseStatus(requestURL, subscriptions.Status, subscriptions.Error)
 }
 
 //GetCategories retrieves forum category information.
-func (w *WhatAPI) GetCategories() (Categories, error) {
+func (w *WhatAPI) GetCategories(ctx context.Context) (Categories, error) {
 	categories := CategoriesResponse{}
 	params := url.Values{}
 	params.Set("type", "main")
@@ -204,15 +448,15 @@ func (w *WhatAPI) GetCategories() (Categories, error) {
 	if err != nil {
 		return categories.Response, err
 	}
-	err = w.GetJSON(requestURL, &categories)
+	err = w.GetJSON(ctx, requestURL, &categories)
 	if err != nil {
 		return categories.Response, err
 	}
-	return categories.Response, checkResponseStatus(categories.Status, categories.Error)
+	return categories.Response, checkResponseStatus(requestURL, categories.Status, categories.Error)
 }
 
 //GetForum retrieves forum information using the provided forum id and parameters.
-func (w *WhatAPI) GetForum(id int, params url.Values) (Forum, error) {
+func (w *WhatAPI) GetForum(ctx context.Context, id int, params url.Values) (Forum, error) {
 	forum := ForumResponse{}
 	params.Set("type", "viewforum")
 	params.Set("forumid", strconv.Itoa(id))
@@ -220,15 +464,15 @@ func (w *WhatAPI) GetForum(id int, params url.Values) (Forum, error) {
 	if err != nil {
 		return forum.Response, err
 	}
-	err = w.GetJSON(requestURL, &forum)
+	err = w.GetJSON(ctx, requestURL, &forum)
 	if err != nil {
 		return forum.Response, err
 	}
-	return forum.Response, checkResponseStatus(forum.Status, forum.Error)
+	return forum.Response, checkResponseStatus(requestURL, forum.Status, forum.Error)
 }
 
 //GetThread retrieves forum thread information using the provided thread id and parameters.
-func (w *WhatAPI) GetThread(id int, params url.Values) (Thread, error) {
+func (w *WhatAPI) GetThread(ctx context.Context, id int, params url.Values) (Thread, error) {
 	thread := ThreadResponse{}
 	params.Set("type", "viewthread")
 	params.Set("threadid", strconv.Itoa(id))
@@ -236,15 +480,15 @@ func (w *WhatAPI) GetThread(id int, params url.Values) (Thread, error) {
 	if err != nil {
 		return thread.Response, err
 	}
-	err = w.GetJSON(requestURL, &thread)
+	err = w.GetJSON(ctx, requestURL, &thread)
 	if err != nil {
 		return thread.Response, err
 	}
-	return thread.Response, checkResponseStatus(thread.Status, thread.Error)
+	return thread.Response, checkResponseStatus(requestURL, thread.Status, thread.Error)
 }
 
 //GetArtistBookmarks retrieves artist bookmark information for the current user.
-func (w *WhatAPI) GetArtistBookmarks() (ArtistBookmarks, error) {
+func (w *WhatAPI) GetArtistBookmarks(ctx context.Context) (ArtistBookmarks, error) {
 	artistBookmarks := ArtistBookmarksResponse{}
 	params := url.Values{}
 	params.Set("type", "artists")
@@ -252,15 +496,15 @@ func (w *WhatAPI) GetArtistBookmarks() (ArtistBookmarks, error) {
 	if err != nil {
 		return artistBookmarks.Response, err
 	}
-	err = w.GetJSON(requestURL, &artistBookmarks)
+	err = w.GetJSON(ctx, requestURL, &artistBookmarks)
 	if err != nil {
 		return artistBookmarks.Response, err
 	}
-	return artistBookmarks.Response, checkResponseStatus(artistBookmarks.Status, artistBookmarks.Error)
+	return artistBookmarks.Response, checkResponseStatus(requestURL, artistBookmarks.Status, artistBookmarks.Error)
 }
 
 //GetTorrentBookmarks retrieves torrent bookmark information for the current user.
-func (w *WhatAPI) GetTorrentBookmarks() (TorrentBookmarks, error) {
+func (w *WhatAPI) GetTorrentBookmarks(ctx context.Context) (TorrentBookmarks, error) {
 	torrentBookmarks := TorrentBookmarksResponse{}
 	params := url.Values{}
 	params.Set("type", "torrents")
@@ -268,165 +512,229 @@ func (w *WhatAPI) GetTorrentBookmarks() (TorrentBookmarks, error) {
 	if err != nil {
 		return torrentBookmarks.Response, err
 	}
-	err = w.GetJSON(requestURL, &torrentBookmarks)
+	err = w.GetJSON(ctx, requestURL, &torrentBookmarks)
 	if err != nil {
 		return torrentBookmarks.Response, err
 	}
-	return torrentBookmarks.Response, checkResponseStatus(torrentBookmarks.Status, torrentBookmarks.Error)
+	return torrentBookmarks.Response, checkResponseStatus(requestURL, torrentBookmarks.Status, torrentBookmarks.Error)
 }
 
 //GetArtist retrieves artist information using the provided artist id and parameters.
-func (w *WhatAPI) GetArtist(id int, params url.Values) (Artist, error) {
+func (w *WhatAPI) GetArtist(ctx context.Context, id int, params url.Values) (Artist, error) {
 	artist := ArtistResponse{}
 	params.Set("id", strconv.Itoa(id))
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "artist", params)
 	if err != nil {
 		return artist.Response, err
 	}
-	err = w.GetJSON(requestURL, &artist)
+	err = w.GetJSON(ctx, requestURL, &artist)
 	if err != nil {
 		return artist.Response, err
 	}
-	return artist.Response, checkResponseStatus(artist.Status, artist.Error)
+	return artist.Response, checkResponseStatus(requestURL, artist.Status, artist.Error)
+}
+
+//GetCollage retrieves collage information using the provided collage id and parameters.
+func (w *WhatAPI) GetCollage(ctx context.Context, id int, params url.Values) (Collage, error) {
+	collage := CollageResponse{}
+	params.Set("id", strconv.Itoa(id))
+	requestURL, err := buildURL(w.baseURL, "ajax.php", "collage", params)
+	if err != nil {
+		return collage.Response, err
+	}
+	err = w.GetJSON(ctx, requestURL, &collage)
+	if err != nil {
+		return collage.Response, err
+	}
+	return collage.Response, checkResponseStatus(requestURL, collage.Status, collage.Error)
 }
 
 //GetRequest retrieves request information using the provided request id and parameters.
-func (w *WhatAPI) GetRequest(id int, params url.Values) (Request, error) {
+func (w *WhatAPI) GetRequest(ctx context.Context, id int, params url.Values) (Request, error) {
 	request := RequestResponse{}
 	params.Set("id", strconv.Itoa(id))
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "request", params)
 	if err != nil {
 		return request.Response, err
 	}
-	err = w.GetJSON(requestURL, &request)
+	err = w.GetJSON(ctx, requestURL, &request)
 	if err != nil {
 		return request.Response, err
 	}
-	return request.Response, checkResponseStatus(request.Status, request.Error)
+	return request.Response, checkResponseStatus(requestURL, request.Status, request.Error)
 }
 
 //GetTorrent retrieves torrent information using the provided torrent id and parameters.
-func (w *WhatAPI) GetTorrent(id int, params url.Values) (Torrent, error) {
+func (w *WhatAPI) GetTorrent(ctx context.Context, id int, params url.Values) (Torrent, error) {
 	torrent := TorrentResponse{}
 	params.Set("id", strconv.Itoa(id))
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "torrent", params)
 	if err != nil {
 		return torrent.Response, err
 	}
-	err = w.GetJSON(requestURL, &torrent)
+	err = w.GetJSON(ctx, requestURL, &torrent)
 	if err != nil {
 		return torrent.Response, err
 	}
-	return torrent.Response, checkResponseStatus(torrent.Status, torrent.Error)
+	return torrent.Response, checkResponseStatus(requestURL, torrent.Status, torrent.Error)
 }
 
 //GetTorrentGroup retrieves torrent group information using the provided torrent group id and parameters.
-func (w *WhatAPI) GetTorrentGroup(id int, params url.Values) (TorrentGroup, error) {
+func (w *WhatAPI) GetTorrentGroup(ctx context.Context, id int, params url.Values) (TorrentGroup, error) {
 	torrentGroup := TorrentGroupResponse{}
 	params.Set("id", strconv.Itoa(id))
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "torrentgroup", params)
 	if err != nil {
 		return torrentGroup.Response, err
 	}
-	err = w.GetJSON(requestURL, &torrentGroup)
+	err = w.GetJSON(ctx, requestURL, &torrentGroup)
 	if err != nil {
 		return torrentGroup.Response, err
 	}
-	return torrentGroup.Response, checkResponseStatus(torrentGroup.Status, torrentGroup.Error)
+	return torrentGroup.Response, checkResponseStatus(requestURL, torrentGroup.Status, torrentGroup.Error)
 }
 
-//SearchTorrents retrieves torrent search results using the provided search string and parameters.
-func (w *WhatAPI) SearchTorrents(searchStr string, params url.Values) (TorrentSearch, error) {
+//GetTorrentComments retrieves the comments on the torrent group with the provided id using the provided parameters, e.g. page.
+func (w *WhatAPI) GetTorrentComments(ctx context.Context, groupID int, params url.Values) (Comments, error) {
+	comments := CommentsResponse{}
+	params.Set("id", strconv.Itoa(groupID))
+	requestURL, err := buildURL(w.baseURL, "ajax.php", "tcomments", params)
+	if err != nil {
+		return comments.Response, err
+	}
+	err = w.GetJSON(ctx, requestURL, &comments)
+	if err != nil {
+		return comments.Response, err
+	}
+	return comments.Response, checkResponseStatus(requestURL, comments.Status, comments.Error)
+}
+
+//SearchTorrents retrieves torrent search results using the provided search string and options.
+func (w *WhatAPI) SearchTorrents(ctx context.Context, searchStr string, options TorrentSearchOptions) (TorrentSearch, error) {
 	torrentSearch := TorrentSearchResponse{}
+	if err := options.Validate(); err != nil {
+		return torrentSearch.Response, err
+	}
+	params := options.values()
 	params.Set("searchstr", searchStr)
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "browse", params)
 	if err != nil {
 		return torrentSearch.Response, err
 	}
-	err = w.GetJSON(requestURL, &torrentSearch)
+	err = w.GetJSON(ctx, requestURL, &torrentSearch)
 	if err != nil {
 		return torrentSearch.Response, err
 	}
-	return torrentSearch.Response, checkResponseStatus(torrentSearch.Status, torrentSearch.Error)
+	return torrentSearch.Response, checkResponseStatus(requestURL, torrentSearch.Status, torrentSearch.Error)
 }
 
-//SearchRequests retrieves request search results using the provided search string and parameters.
-func (w *WhatAPI) SearchRequests(searchStr string, params url.Values) (RequestsSearch, error) {
+//SearchRequests retrieves request search results using the provided search string and options.
+func (w *WhatAPI) SearchRequests(ctx context.Context, searchStr string, options RequestSearchOptions) (RequestsSearch, error) {
 	requestsSearch := RequestsSearchResponse{}
+	if err := options.Validate(); err != nil {
+		return requestsSearch.Response, err
+	}
+	params := options.values()
 	params.Set("search", searchStr)
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "requests", params)
 	if err != nil {
 		return requestsSearch.Response, err
 	}
-	err = w.GetJSON(requestURL, &requestsSearch)
+	err = w.GetJSON(ctx, requestURL, &requestsSearch)
 	if err != nil {
 		return requestsSearch.Response, err
 	}
-	return requestsSearch.Response, checkResponseStatus(requestsSearch.Status, requestsSearch.Error)
+	return requestsSearch.Response, checkResponseStatus(requestURL, requestsSearch.Status, requestsSearch.Error)
 }
 
-//SearchUsers retrieves user search results using the provided search string and parameters.
-func (w *WhatAPI) SearchUsers(searchStr string, params url.Values) (UserSearch, error) {
+//SearchUsers retrieves user search results using the provided search string and options.
+func (w *WhatAPI) SearchUsers(ctx context.Context, searchStr string, options UserSearchOptions) (UserSearch, error) {
 	userSearch := UserSearchResponse{}
+	if err := options.Validate(); err != nil {
+		return userSearch.Response, err
+	}
+	params := options.values()
 	params.Set("search", searchStr)
 	requestURL, err := buildURL(w.baseURL, "ajax.php", "usersearch", params)
 	if err != nil {
 		return userSearch.Response, err
 	}
-	err = w.GetJSON(requestURL, &userSearch)
+	err = w.GetJSON(ctx, requestURL, &userSearch)
 	if err != nil {
 		return userSearch.Response, err
 	}
-	return userSearch.Response, checkResponseStatus(userSearch.Status, userSearch.Error)
+	return userSearch.Response, checkResponseStatus(requestURL, userSearch.Status, userSearch.Error)
 }
 
-//GetTopTenTorrents retrieves "top ten torrents" information using the provided parameters.
-func (w *WhatAPI) GetTopTenTorrents(params url.Values) (TopTenTorrents, error) {
+//GetUser retrieves user profile information using the provided user id.
+func (w *WhatAPI) GetUser(ctx context.Context, id int) (User, error) {
+	user := UserResponse{}
+	params := url.Values{}
+	params.Set("id", strconv.Itoa(id))
+	requestURL, err := buildURL(w.baseURL, "ajax.php", "user", params)
+	if err != nil {
+		return user.Response, err
+	}
+	err = w.GetJSON(ctx, requestURL, &user)
+	if err != nil {
+		return user.Response, err
+	}
+	return user.Response, checkResponseStatus(requestURL, user.Status, user.Error)
+}
+
+//GetTopTenTorrents retrieves "top ten torrents" information using the provided options.
+func (w *WhatAPI) GetTopTenTorrents(ctx context.Context, options TopTenOptions) (TopTenTorrents, error) {
 	topTenTorrents := TopTenTorrentsResponse{}
-	params.Set("type", "torrents")
-	requestURL, err := buildURL(w.baseURL, "ajax.php", "top10", params)
+	if err := options.validate("torrents"); err != nil {
+		return topTenTorrents.Response, err
+	}
+	requestURL, err := buildURL(w.baseURL, "ajax.php", "top10", options.values("torrents"))
 	if err != nil {
 		return topTenTorrents.Response, err
 	}
-	err = w.GetJSON(requestURL, &topTenTorrents)
+	err = w.GetJSON(ctx, requestURL, &topTenTorrents)
 	if err != nil {
 		return topTenTorrents.Response, err
 	}
-	return topTenTorrents.Response, checkResponseStatus(topTenTorrents.Status, topTenTorrents.Error)
+	return topTenTorrents.Response, checkResponseStatus(requestURL, topTenTorrents.Status, topTenTorrents.Error)
 }
 
-//GetTopTenTags retrieves "top ten tags" information using the provided parameters.
-func (w *WhatAPI) GetTopTenTags(params url.Values) (TopTenTags, error) {
+//GetTopTenTags retrieves "top ten tags" information using the provided options.
+func (w *WhatAPI) GetTopTenTags(ctx context.Context, options TopTenOptions) (TopTenTags, error) {
 	topTenTags := TopTenTagsResponse{}
-	params.Set("type", "tags")
-	requestURL, err := buildURL(w.baseURL, "ajax.php", "top10", params)
+	if err := options.validate("tags"); err != nil {
+		return topTenTags.Response, err
+	}
+	requestURL, err := buildURL(w.baseURL, "ajax.php", "top10", options.values("tags"))
 	if err != nil {
 		return topTenTags.Response, err
 	}
-	err = w.GetJSON(requestURL, &topTenTags)
+	err = w.GetJSON(ctx, requestURL, &topTenTags)
 	if err != nil {
 		return topTenTags.Response, err
 	}
-	return topTenTags.Response, checkResponseStatus(topTenTags.Status, topTenTags.Error)
+	return topTenTags.Response, checkResponseStatus(requestURL, topTenTags.Status, topTenTags.Error)
 }
 
-//GetTopTenUsers retrieves "top tem users" information using the provided parameters.
-func (w *WhatAPI) GetTopTenUsers(params url.Values) (TopTenUsers, error) {
+//GetTopTenUsers retrieves "top tem users" information using the provided options.
+func (w *WhatAPI) GetTopTenUsers(ctx context.Context, options TopTenOptions) (TopTenUsers, error) {
 	topTenUsers := TopTenUsersResponse{}
-	params.Set("type", "users")
-	requestURL, err := buildURL(w.baseURL, "ajax.php", "top10", params)
+	if err := options.validate("users"); err != nil {
+		return topTenUsers.Response, err
+	}
+	requestURL, err := buildURL(w.baseURL, "ajax.php", "top10", options.values("users"))
 	if err != nil {
 		return topTenUsers.Response, err
 	}
-	err = w.GetJSON(requestURL, &topTenUsers)
+	err = w.GetJSON(ctx, requestURL, &topTenUsers)
 	if err != nil {
 		return topTenUsers.Response, err
 	}
-	return topTenUsers.Response, checkResponseStatus(topTenUsers.Status, topTenUsers.Error)
+	return topTenUsers.Response, checkResponseStatus(requestURL, topTenUsers.Status, topTenUsers.Error)
 }
 
 //GetSimilarArtists retrieves similar artist information using the provided artist id and limit.
-func (w *WhatAPI) GetSimilarArtists(id, limit int) (SimilarArtists, error) {
+func (w *WhatAPI) GetSimilarArtists(ctx context.Context, id, limit int) (SimilarArtists, error) {
 	similarArtists := SimilarArtists{}
 	params := url.Values{}
 	params.Set("id", strconv.Itoa(id))
@@ -435,7 +743,7 @@ func (w *WhatAPI) GetSimilarArtists(id, limit int) (SimilarArtists, error) {
 	if err != nil {
 		return similarArtists, err
 	}
-	err = w.GetJSON(requestURL, &similarArtists)
+	err = w.GetJSON(ctx, requestURL, &similarArtists)
 	if err != nil {
 		return similarArtists, err
 	}
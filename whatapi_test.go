@@ -0,0 +1,463 @@
+package whatapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	fakeUsername = "user"
+	fakePassword = "hunter2"
+	fakeUserID   = 42
+	fakeAuthKey  = "authkey"
+	fakePassKey  = "passkey"
+	fakeToken    = "token"
+)
+
+//fakeSite is a Gazelle site with just enough of login.php, ajax.php and the page actions to exercise the client's session handling.
+type fakeSite struct {
+	mu       sync.Mutex
+	password string
+	//sessions maps session cookies to whether ajax.php still accepts them.
+	sessions     map[string]bool
+	nextSession  int
+	logins       int
+	failedLogins int
+	//missingKeys counts page actions and downloads sent without the authkey or passkey.
+	missingKeys int
+}
+
+func newFakeSite() *fakeSite {
+	return &fakeSite{password: fakePassword, sessions: map[string]bool{}}
+}
+
+//expire ends every session, as if they had all timed out.
+func (f *fakeSite) expire() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions = map[string]bool{}
+}
+
+//expireAjax makes ajax.php reject every current session while the site's pages still accept them.
+func (f *fakeSite) expireAjax() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for session := range f.sessions {
+		f.sessions[session] = false
+	}
+}
+
+func (f *fakeSite) setPassword(password string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.password = password
+}
+
+func (f *fakeSite) counts() (logins, failedLogins, missingKeys int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.logins, f.failedLogins, f.missingKeys
+}
+
+//session returns whether r carries a session cookie the site knows and whether ajax.php accepts it.
+func (f *fakeSite) session(r *http.Request) (known, ajax bool) {
+	cookie, err := r.Cookie("session")
+	if err != nil {
+		return false, false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ajax, known = f.sessions[cookie.Value]
+	return known, ajax
+}
+
+func (f *fakeSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/login.php":
+		if r.Method == http.MethodPost {
+			f.login(w, r)
+			return
+		}
+		fmt.Fprint(w, `<html><title>Login</title><form><input name="username"></form></html>`)
+	case "/index.php":
+		fmt.Fprint(w, `<html><title>Index</title></html>`)
+	case "/logout.php":
+		if cookie, err := r.Cookie("session"); err == nil {
+			f.mu.Lock()
+			delete(f.sessions, cookie.Value)
+			f.mu.Unlock()
+		}
+		http.Redirect(w, r, "/login.php", http.StatusFound)
+	case "/ajax.php":
+		_, ajax := f.session(r)
+		if !ajax && r.Header.Get("Authorization") != fakeToken {
+			http.Redirect(w, r, "/login.php", http.StatusFound)
+			return
+		}
+		f.ajax(w, r)
+	case "/inbox.php":
+		if known, _ := f.session(r); !known {
+			http.Redirect(w, r, "/login.php", http.StatusFound)
+			return
+		}
+		r.ParseForm()
+		if r.PostForm.Get("auth") != fakeAuthKey {
+			f.mu.Lock()
+			f.missingKeys++
+			f.mu.Unlock()
+			fmt.Fprint(w, `<html><title>Error 403</title><div class="box pad"><p>Invalid authorization key.</p></div></html>`)
+			return
+		}
+		fmt.Fprint(w, `<html><title>Inbox</title></html>`)
+	case "/bookmarks.php":
+		if known, _ := f.session(r); !known {
+			http.Redirect(w, r, "/login.php", http.StatusFound)
+			return
+		}
+		switch r.URL.Query().Get("type") {
+		case "collages":
+			fmt.Fprint(w, `<html><title>Bookmarked collages</title><table>`+
+				`<tr class="rowa"><td><a href="collages.php?id=7">Best &amp; Worst</a></td></tr>`+
+				`<tr class="rowb"><td><a href="collages.php?id=9">Jazz</a> <a href="collages.php?id=7">Best &amp; Worst</a></td></tr>`+
+				`</table></html>`)
+		case "requests":
+			fmt.Fprint(w, `<html><title>Bookmarked requests</title><table>`+
+				`<tr class="rowa"><td><a href="artist.php?id=3">Tool</a> - <a href="requests.php?action=view&amp;id=12">Lateralus [2001]</a></td></tr>`+
+				`</table></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeSite) login(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.PostForm.Get("username") != fakeUsername || r.PostForm.Get("password") != f.password {
+		f.failedLogins++
+		fmt.Fprint(w, `<html><title>Login</title><p>Your username or password was incorrect.</p></html>`)
+		return
+	}
+	f.logins++
+	f.nextSession++
+	session := strconv.Itoa(f.nextSession)
+	f.sessions[session] = true
+	http.SetCookie(w, &http.Cookie{Name: "session", Value: session, Path: "/"})
+	http.Redirect(w, r, "/index.php", http.StatusFound)
+}
+
+func (f *fakeSite) ajax(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var response interface{}
+	switch query.Get("action") {
+	case "index":
+		response = Account{Username: fakeUsername, ID: fakeUserID, AuthKey: fakeAuthKey, PassKey: fakePassKey}
+	case "inbox":
+		page, err := strconv.Atoi(query.Get("page"))
+		if err != nil {
+			page = 1
+		}
+		response = Mailbox{CurrentPage: page, Pages: 3}
+	case "bookmarks":
+		fmt.Fprint(w, `<html><title>Bookmarks</title></html>`)
+		return
+	default:
+		json.NewEncoder(w).Encode(map[string]string{"status": "failure", "error": "bad parameters"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "response": response})
+}
+
+func newTestClient(t *testing.T, site *fakeSite, options ...Option) *WhatAPI {
+	t.Helper()
+	server := httptest.NewServer(site)
+	t.Cleanup(server.Close)
+	w, err := NewWhatAPI(server.URL+"/", append([]Option{WithRateLimiter(nil)}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+//hammer runs f concurrently from n goroutines and returns the errors they reported.
+func hammer(n int, f func(i int) error) []error {
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = f(i)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestConcurrentGetJSON(t *testing.T) {
+	site := newFakeSite()
+	w := newTestClient(t, site)
+	ctx := context.Background()
+	if err := w.Login(ctx, fakeUsername, fakePassword); err != nil {
+		t.Fatal(err)
+	}
+	errs := hammer(20, func(i int) error {
+		for j := 0; j < 5; j++ {
+			account, err := w.GetAccount(ctx)
+			if err != nil {
+				return err
+			}
+			if account.AuthKey != fakeAuthKey {
+				return fmt.Errorf("authkey %q", account.AuthKey)
+			}
+			mailbox, err := w.GetMailbox(ctx, nil)
+			if err != nil {
+				return err
+			}
+			if mailbox.Pages != 3 {
+				return fmt.Errorf("mailbox pages %d", mailbox.Pages)
+			}
+		}
+		return nil
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestConcurrentLoginLogout(t *testing.T) {
+	site := newFakeSite()
+	w := newTestClient(t, site)
+	ctx := context.Background()
+	errs := hammer(8, func(i int) error {
+		for j := 0; j < 10; j++ {
+			if err := w.Login(ctx, fakeUsername, fakePassword); err != nil {
+				return err
+			}
+			//Other goroutines log out at any time, so requests may find the client logged out or the session ended.
+			if _, err := w.GetAccount(ctx); err != nil && !errors.Is(err, ErrNotLoggedIn) && !errors.Is(err, ErrSessionExpired) {
+				return err
+			}
+			if _, err := w.CreateDownloadURL(1); err != nil && !errors.Is(err, ErrNotLoggedIn) {
+				return err
+			}
+			if err := w.Logout(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if err := w.Login(ctx, fakeUsername, fakePassword); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.GetAccount(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloginOnce(t *testing.T) {
+	site := newFakeSite()
+	w := newTestClient(t, site, WithCredentials(StaticCredentials(fakeUsername, fakePassword)))
+	ctx := context.Background()
+	if err := w.Login(ctx, fakeUsername, fakePassword); err != nil {
+		t.Fatal(err)
+	}
+	site.expire()
+	errs := hammer(20, func(i int) error {
+		_, err := w.GetAccount(ctx)
+		return err
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if logins, _, _ := site.counts(); logins != 2 {
+		t.Errorf("site saw %d logins, want 2", logins)
+	}
+}
+
+func TestFailedReloginNotRepeated(t *testing.T) {
+	site := newFakeSite()
+	w := newTestClient(t, site, WithCredentials(StaticCredentials(fakeUsername, fakePassword)))
+	ctx := context.Background()
+	if err := w.Login(ctx, fakeUsername, fakePassword); err != nil {
+		t.Fatal(err)
+	}
+	site.setPassword("rotated")
+	site.expire()
+	errs := hammer(20, func(i int) error {
+		_, err := w.GetAccount(ctx)
+		return err
+	})
+	for _, err := range errs {
+		if !errors.Is(err, ErrSessionExpired) && !errors.Is(err, ErrNotLoggedIn) {
+			t.Errorf("GetAccount = %v, want ErrSessionExpired or ErrNotLoggedIn", err)
+		}
+	}
+	if _, failed, _ := site.counts(); failed != 1 {
+		t.Errorf("site saw %d failed logins, want 1", failed)
+	}
+}
+
+func TestPageActionsDuringRenewal(t *testing.T) {
+	site := newFakeSite()
+	w := newTestClient(t, site, WithCredentials(StaticCredentials(fakeUsername, fakePassword)))
+	ctx := context.Background()
+	if err := w.Login(ctx, fakeUsername, fakePassword); err != nil {
+		t.Fatal(err)
+	}
+	errs := hammer(9, func(i int) error {
+		for j := 0; j < 20; j++ {
+			if i == 0 {
+				site.expireAjax()
+				if _, err := w.GetAccount(ctx); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := w.SendMessage(ctx, 1, "subject", "body"); err != nil {
+				return err
+			}
+			downloadURL, err := w.CreateDownloadURL(1)
+			if err != nil {
+				return err
+			}
+			if !containsAll(downloadURL, "authkey="+fakeAuthKey, "torrent_pass="+fakePassKey) {
+				return fmt.Errorf("download URL %s is missing keys", downloadURL)
+			}
+		}
+		return nil
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if _, _, missing := site.counts(); missing != 0 {
+		t.Errorf("site saw %d page actions without the authkey", missing)
+	}
+}
+
+func containsAll(s string, parts ...string) bool {
+	for _, part := range parts {
+		if !strings.Contains(s, part) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTokenSessionPageAction(t *testing.T) {
+	site := newFakeSite()
+	w := newTestClient(t, site)
+	ctx := context.Background()
+	if err := w.LoginWithToken(ctx, fakeToken); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SendMessage(ctx, 1, "subject", "body"); !errors.Is(err, ErrCookieSessionRequired) {
+		t.Fatalf("SendMessage = %v, want ErrCookieSessionRequired", err)
+	}
+	if _, err := w.GetAccount(ctx); err != nil {
+		t.Fatalf("GetAccount after a page action: %v", err)
+	}
+}
+
+func TestNonJSONAnswerKeepsSession(t *testing.T) {
+	site := newFakeSite()
+	w := newTestClient(t, site, WithCredentials(StaticCredentials(fakeUsername, fakePassword)))
+	ctx := context.Background()
+	if err := w.Login(ctx, fakeUsername, fakePassword); err != nil {
+		t.Fatal(err)
+	}
+	requestURL, err := buildURL(w.baseURL, "ajax.php", "bookmarks", map[string][]string{"type": {"collages"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response map[string]interface{}
+	var apiErr *APIError
+	if err := w.GetJSON(ctx, requestURL, &response); !errors.As(err, &apiErr) {
+		t.Fatalf("GetJSON = %v, want an APIError", err)
+	}
+	if logins, _, _ := site.counts(); logins != 1 {
+		t.Errorf("site saw %d logins, want 1", logins)
+	}
+	if _, err := w.GetAccount(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBookmarkPages(t *testing.T) {
+	site := newFakeSite()
+	w := newTestClient(t, site)
+	ctx := context.Background()
+	if err := w.Login(ctx, fakeUsername, fakePassword); err != nil {
+		t.Fatal(err)
+	}
+	collages, err := w.GetCollageBookmarks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCollages := []BookmarkedCollage{{7, "Best & Worst"}, {9, "Jazz"}}
+	if !reflect.DeepEqual(collages, wantCollages) {
+		t.Errorf("GetCollageBookmarks = %+v, want %+v", collages, wantCollages)
+	}
+	requests, err := w.GetRequestBookmarks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRequests := []BookmarkedRequest{{12, "Lateralus [2001]"}}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("GetRequestBookmarks = %+v, want %+v", requests, wantRequests)
+	}
+}
+
+//failingStore is a SessionStore that cannot save sessions.
+type failingStore struct{}
+
+func (failingStore) Load(ctx context.Context) (Session, error) { return Session{}, ErrNoSession }
+
+func (failingStore) Save(ctx context.Context, session Session) error { return errors.New("disk full") }
+
+func (failingStore) Clear(ctx context.Context) error { return nil }
+
+func TestSessionSaveFailure(t *testing.T) {
+	site := newFakeSite()
+	w := newTestClient(t, site,
+		WithCredentials(StaticCredentials(fakeUsername, fakePassword)),
+		WithSessionStore(failingStore{}),
+	)
+	ctx := context.Background()
+	if err := w.Login(ctx, fakeUsername, fakePassword); !errors.Is(err, ErrSessionNotSaved) {
+		t.Fatalf("Login = %v, want ErrSessionNotSaved", err)
+	}
+	if _, err := w.GetAccount(ctx); err != nil {
+		t.Fatalf("GetAccount after an unsaved login: %v", err)
+	}
+	site.expire()
+	if _, err := w.GetAccount(ctx); err != nil {
+		t.Fatalf("GetAccount after renewing an unsaved session: %v", err)
+	}
+	if logins, _, _ := site.counts(); logins != 2 {
+		t.Errorf("site saw %d logins, want 2", logins)
+	}
+}